@@ -0,0 +1,81 @@
+package concepts
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	UPPAuthority             = "http://api.ft.com/system/UPP"
+	TMEAuthority             = "http://api.ft.com/system/FT-TME"
+	SmartlogicAuthority      = "http://api.ft.com/system/SMARTLOGIC"
+	FactsetAuthority         = "http://api.ft.com/system/FACTSET"
+	LEIAuthority             = "http://api.ft.com/system/LEI"
+	ManagedLocationAuthority = "http://api.ft.com/system/MANAGEDLOCATION"
+	ISO31661Authority        = "http://api.ft.com/system/ISO-3166-1"
+	GeonamesAuthority        = "http://api.ft.com/system/GEONAMES"
+	WikidataAuthority        = "http://api.ft.com/system/WIKIDATA"
+	DBPediaAuthority         = "http://api.ft.com/system/DBPEDIA"
+	NAICSAuthority           = "http://api.ft.com/system/NAICS"
+)
+
+// Authority is an issuer of ids which are concorded to UPP concepts
+type Authority struct {
+	URI string `json:"uri"`
+	// Alias is a short name which may be used in place of the URI
+	Alias string `json:"alias"`
+
+	idFormat *regexp.Regexp
+}
+
+var (
+	uuidFormat = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// authorities are those whose identifiers public concordances holds
+	authorities = []Authority{
+		{URI: UPPAuthority, Alias: "UPP", idFormat: uuidFormat},
+		{URI: TMEAuthority, Alias: "TME", idFormat: regexp.MustCompile(`^[A-Za-z0-9+/]+={0,2}-[A-Za-z0-9+/]+={0,2}$`)},
+		{URI: SmartlogicAuthority, Alias: "SMARTLOGIC", idFormat: uuidFormat},
+		{URI: FactsetAuthority, Alias: "FACTSET", idFormat: regexp.MustCompile(`^[0-9A-Z]{6}-E$`)},
+		{URI: LEIAuthority, Alias: "LEI", idFormat: regexp.MustCompile(`^[0-9A-Z]{18}[0-9]{2}$`)},
+		{URI: ManagedLocationAuthority, Alias: "MANAGEDLOCATION"},
+		{URI: ISO31661Authority, Alias: "ISO-3166-1"},
+		{URI: GeonamesAuthority, Alias: "GEONAMES"},
+		{URI: WikidataAuthority, Alias: "WIKIDATA"},
+		{URI: DBPediaAuthority, Alias: "DBPEDIA"},
+		{URI: NAICSAuthority, Alias: "NAICS"},
+	}
+)
+
+// Authorities returns every supported authority
+func Authorities() []Authority {
+	return append([]Authority(nil), authorities...)
+}
+
+// ResolveAuthority returns the URI of the supported authority given by either its URI or its case insensitive alias.
+// NoAuthority resolves to itself.
+func ResolveAuthority(authority string) (string, bool) {
+	if authority == NoAuthority {
+		return NoAuthority, true
+	}
+	for _, a := range authorities {
+		if authority == a.URI || strings.EqualFold(authority, a.Alias) {
+			return a.URI, true
+		}
+	}
+	return "", false
+}
+
+// IsValidID reports whether the id has the format of the ids issued by the authority, ids without an authority are UPP uuids.
+// Ids of authorities without a known format are always valid.
+func IsValidID(authority, id string) bool {
+	if authority == NoAuthority {
+		return uuidFormat.MatchString(id)
+	}
+	for _, a := range authorities {
+		if a.URI == authority {
+			return a.idFormat == nil || a.idFormat.MatchString(id)
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package concepts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidID(t *testing.T) {
+	tests := []struct {
+		authority string
+		id        string
+		valid     bool
+	}{
+		{NoAuthority, "1f2c7277-5f74-3397-b852-92bcb1096021", true},
+		{NoAuthority, "1F2C7277-5F74-3397-B852-92BCB1096021", true},
+		{NoAuthority, "1f2c7277-5f74-3397-b852-92bcb109602", false},
+		{NoAuthority, "1f2c7277-5f74-3397-b852-92bcb1096021x", false},
+		{NoAuthority, "not-a-uuid", false},
+		{UPPAuthority, "1f2c7277-5f74-3397-b852-92bcb1096021", true},
+		{UPPAuthority, "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=", false},
+		{SmartlogicAuthority, "5d0fedcd-20e5-48d7-953e-b8e72865828c", true},
+		{SmartlogicAuthority, "5d0fedcd", false},
+		{TMEAuthority, "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=", true},
+		{TMEAuthority, "MTQ4-U2VjdGlvbnM=", true},
+		{TMEAuthority, "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==", false},
+		{TMEAuthority, "1f2c7277-5f74-3397-b852-92bcb1096021", false},
+		{FactsetAuthority, "000C7F-E", true},
+		{FactsetAuthority, "000C7F", false},
+		{LEIAuthority, "213800BJPX8V9HVY1Y11", true},
+		{LEIAuthority, "213800BJPX8V9HVY1Y1", false},
+		{GeonamesAuthority, "2643743", true},
+		{"http://api.ft.com/system/UNKNOWN", "anything", true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.valid, IsValidID(test.authority, test.id), "%v %v", test.authority, test.id)
+	}
+}
+
+func TestResolveAuthority(t *testing.T) {
+	tests := []struct {
+		authority string
+		uri       string
+		supported bool
+	}{
+		{NoAuthority, NoAuthority, true},
+		{"http://api.ft.com/system/FT-TME", TMEAuthority, true},
+		{"TME", TMEAuthority, true},
+		{"tme", TMEAuthority, true},
+		{"UPP", UPPAuthority, true},
+		{"Smartlogic", SmartlogicAuthority, true},
+		{"FACTSET", FactsetAuthority, true},
+		{"lei", LEIAuthority, true},
+		{"http://api.ft.com/system/MANAGEDLOCATION", ManagedLocationAuthority, true},
+		{"ISO-3166-1", ISO31661Authority, true},
+		{"Geonames", GeonamesAuthority, true},
+		{"WIKIDATA", WikidataAuthority, true},
+		{"DBPedia", DBPediaAuthority, true},
+		{"NAICS", NAICSAuthority, true},
+		{"http://api.ft.com/system/UNKNOWN", "", false},
+		{"FT-TME", "", false},
+	}
+
+	for _, test := range tests {
+		uri, supported := ResolveAuthority(test.authority)
+		assert.Equal(t, test.uri, uri, test.authority)
+		assert.Equal(t, test.supported, supported, test.authority)
+	}
+}
+
+func TestAuthoritiesCannotBeModified(t *testing.T) {
+	Authorities()[0].URI = "modified"
+	assert.Equal(t, UPPAuthority, Authorities()[0].URI)
+}
@@ -0,0 +1,145 @@
+package concepts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerPolicy describes when calls to an upstream API stop being made, after too many of them failed
+type CircuitBreakerPolicy struct {
+	// FailureRate is the share of failed requests within a window which opens the circuit
+	FailureRate float64
+	// MinRequests is the number of requests a window must contain before the circuit can open
+	MinRequests int
+	// Window is the period over which the failure rate is measured
+	Window time.Duration
+	// OpenDuration is how long the circuit stays open, before a probe request is let through
+	OpenDuration time.Duration
+}
+
+// WithCircuitBreaker fails requests to the upstream API fast while it is failing, according to the policy
+func WithCircuitBreaker(policy CircuitBreakerPolicy) Option {
+	return func(a *httpAPI) {
+		if policy.FailureRate > 0 && policy.MinRequests > 0 {
+			a.breaker = newCircuitBreaker(policy)
+		}
+	}
+}
+
+// CircuitOpenError is returned without calling the upstream API, while its circuit breaker is open
+type CircuitOpenError struct {
+	Upstream string
+	// RetryAfter is the time remaining until the circuit breaker lets a request through again
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %v is open", e.Upstream)
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+type circuitBreaker struct {
+	sync.Mutex
+	policy      CircuitBreakerPolicy
+	now         func() time.Time
+	state       circuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probing     bool
+}
+
+func newCircuitBreaker(policy CircuitBreakerPolicy) *circuitBreaker {
+	return &circuitBreaker{policy: policy, now: time.Now}
+}
+
+// allow reports whether a request may be made, and how long until one may be made if not
+func (b *circuitBreaker) allow() (bool, time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.currentState() {
+	case circuitOpen:
+		return false, b.openedAt.Add(b.policy.OpenDuration).Sub(b.now())
+	case circuitHalfOpen:
+		if b.probing {
+			return false, b.policy.OpenDuration
+		}
+		b.probing = true
+	}
+	return true, 0
+}
+
+// record accounts for the outcome of a request which was allowed
+func (b *circuitBreaker) record(failed bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	now := b.now()
+	if b.currentState() == circuitHalfOpen {
+		b.probing = false
+		if failed {
+			b.state, b.openedAt = circuitOpen, now
+			return
+		}
+		b.state = circuitClosed
+		b.windowStart, b.requests, b.failures = now, 0, 0
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.policy.Window {
+		b.windowStart, b.requests, b.failures = now, 0, 0
+	}
+
+	b.requests++
+	if failed {
+		b.failures++
+	}
+
+	if b.requests >= b.policy.MinRequests && float64(b.failures)/float64(b.requests) >= b.policy.FailureRate {
+		b.state, b.openedAt = circuitOpen, now
+	}
+}
+
+func (b *circuitBreaker) currentState() circuitState {
+	if b.state == circuitOpen && !b.now().Before(b.openedAt.Add(b.policy.OpenDuration)) {
+		b.state = circuitHalfOpen
+	}
+	return b.state
+}
+
+func (b *circuitBreaker) current() circuitState {
+	b.Lock()
+	defer b.Unlock()
+	return b.currentState()
+}
+
+// isUpstreamFailure reports whether the outcome of a request counts against the upstream API
+func isUpstreamFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,156 @@
+package concepts
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/internal-concordances/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBreakerPolicy = CircuitBreakerPolicy{
+	FailureRate:  0.5,
+	MinRequests:  4,
+	Window:       time.Minute,
+	OpenDuration: 10 * time.Second,
+}
+
+func TestCircuitBreakerOpensAtFailureRate(t *testing.T) {
+	breaker := newCircuitBreaker(testBreakerPolicy)
+
+	breaker.record(true)
+	breaker.record(true)
+	breaker.record(false)
+	assert.Equal(t, circuitClosed, breaker.current()) // not enough requests yet
+
+	breaker.record(false)
+	assert.Equal(t, circuitOpen, breaker.current())
+
+	allowed, retryAfter := breaker.allow()
+	assert.False(t, allowed)
+	assert.Equal(t, 10*time.Second, retryAfter.Round(time.Second))
+}
+
+func TestCircuitBreakerStaysClosedBelowFailureRate(t *testing.T) {
+	breaker := newCircuitBreaker(testBreakerPolicy)
+
+	for i := 0; i < 10; i++ {
+		breaker.record(i%3 == 2)
+	}
+
+	assert.Equal(t, circuitClosed, breaker.current())
+}
+
+func TestCircuitBreakerWindowResets(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(testBreakerPolicy)
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(true)
+	breaker.record(true)
+	breaker.record(true)
+
+	now = now.Add(time.Minute)
+	breaker.record(true)
+
+	assert.Equal(t, circuitClosed, breaker.current())
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(testBreakerPolicy)
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		breaker.record(true)
+	}
+	assert.Equal(t, circuitOpen, breaker.current())
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, circuitHalfOpen, breaker.current())
+
+	allowed, _ := breaker.allow()
+	assert.True(t, allowed)
+	allowed, _ = breaker.allow() // only one probe at a time
+	assert.False(t, allowed)
+
+	breaker.record(true)
+	assert.Equal(t, circuitOpen, breaker.current())
+
+	now = now.Add(10 * time.Second)
+	allowed, _ = breaker.allow()
+	assert.True(t, allowed)
+
+	breaker.record(false)
+	assert.Equal(t, circuitClosed, breaker.current())
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	concordances := NewConcordances(&http.Client{}, server.URL, WithCircuitBreaker(testBreakerPolicy))
+	for i := 0; i < 4; i++ {
+		_, err := concordances.GetConcordances(context.Background(), "tid_TestCircuitBreakerFailsFast", NoAuthority, "uuid-1")
+		assert.EqualError(t, err, "500 Internal Server Error: Failed to decode message from response")
+	}
+
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestCircuitBreakerFailsFast", NoAuthority, "uuid-1")
+
+	var circuitErr *CircuitOpenError
+	require.True(t, errors.As(err, &circuitErr))
+	assert.Equal(t, "public-concordances-api", circuitErr.Upstream)
+	assert.EqualError(t, err, "circuit breaker for public-concordances-api is open")
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
+
+func TestCircuitBreakerStateInCheck(t *testing.T) {
+	gtgServerMock := newConceptSearchAPIGTGMock(t, http.StatusOK)
+	defer gtgServerMock.Close()
+
+	search := NewSearch(&http.Client{}, gtgServerMock.URL, WithCircuitBreaker(testBreakerPolicy))
+	breaker := search.(*conceptSearchAPI).breaker
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	check := search.Check()
+	assertSearchCheckConsistency(t, check)
+
+	msg, err := check.Checker()
+	assert.NoError(t, err)
+	assert.Equal(t, "Concept Search API is good to go", msg)
+
+	for i := 0; i < 4; i++ {
+		breaker.record(true)
+	}
+
+	msg, err = check.Checker()
+	assert.NoError(t, err)
+	assert.Equal(t, "Concept Search API is good to go, circuit breaker is open", msg)
+
+	gtg := health.NewHealthService("appSystemCode", "appName", "appDescription", check).GTG()
+	assert.True(t, gtg.GoodToGo, "an open circuit breaker must not make the service unready")
+
+	now = now.Add(10 * time.Second)
+
+	msg, err = check.Checker()
+	assert.NoError(t, err)
+	assert.Equal(t, "Concept Search API is good to go, circuit breaker is half-open", msg)
+}
+
+func TestCircuitBreakerIgnoresCancelledRequests(t *testing.T) {
+	assert.False(t, isUpstreamFailure(nil, context.Canceled))
+	assert.True(t, isUpstreamFailure(nil, context.DeadlineExceeded))
+	assert.True(t, isUpstreamFailure(&http.Response{StatusCode: http.StatusBadGateway}, nil))
+	assert.False(t, isUpstreamFailure(&http.Response{StatusCode: http.StatusNotFound}, nil))
+}
@@ -0,0 +1,209 @@
+package concepts
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is implemented by the caching Search and Concordances, so their entries can be inspected and purged
+type Cache interface {
+	Name() string
+	Stats() CacheStats
+	// Purge removes the entries for the given ids, or every entry of the authority if no ids are given.
+	// Ids are purged across all authorities when the authority is NoAuthority.
+	Purge(authority string, ids ...string) int
+	Flush() int
+}
+
+// ConcordancesCache is implemented by the caching Concordances, whose entries point to the canonical uuids of concepts cached by Search
+type ConcordancesCache interface {
+	Cache
+	// CanonicalUUIDs returns the uuids concorded by the entries which Purge would remove for the same authority and ids
+	CanonicalUUIDs(authority string, ids ...string) []string
+}
+
+// CacheStats summarises the usage of a Cache
+type CacheStats struct {
+	Size      int     `json:"size"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	HitRatio  float64 `json:"hitRatio"`
+	Evictions uint64  `json:"evictions"`
+}
+
+// cache is a size bounded, least recently used store whose entries expire after a ttl
+type cache struct {
+	sync.Mutex
+	maxEntries int
+	entries    *list.List
+	items      map[string]*list.Element
+	now        func() time.Time
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type cacheEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+	discardAt time.Time
+}
+
+func newCache(maxEntries int) *cache {
+	return &cache{
+		maxEntries: maxEntries,
+		entries:    list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// get returns the value stored against the key, if it is present and has not yet expired
+func (c *cache) get(key string) (interface{}, bool) {
+	entry, found := c.getEntry(key)
+	if !found || !c.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// getEntry returns the entry stored against the key, including expired entries which are still within their stale period
+func (c *cache) getEntry(key string) (cacheEntry, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	el, found := c.items[key]
+	if !found {
+		c.misses++
+		return cacheEntry{}, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	now := c.now()
+	if !now.Before(entry.discardAt) {
+		c.misses++
+		c.removeElement(el)
+		return cacheEntry{}, false
+	}
+
+	if now.Before(entry.expiresAt) {
+		c.hits++
+	} else {
+		c.misses++
+	}
+
+	c.entries.MoveToFront(el)
+	return *entry, true
+}
+
+// set stores the value against the key for the given ttl, evicting the least recently used entry if the cache is full
+func (c *cache) set(key string, value interface{}, ttl time.Duration) {
+	c.setWithStale(key, value, ttl, 0)
+}
+
+// setWithStale stores the value against the key for the given ttl, after which it is retained as stale for a further staleTTL
+func (c *cache) setWithStale(key string, value interface{}, ttl time.Duration, staleTTL time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	discardAt := expiresAt.Add(staleTTL)
+	if el, found := c.items[key]; found {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		entry.discardAt = discardAt
+		c.entries.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.entries.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt, discardAt: discardAt})
+	if c.maxEntries > 0 && c.entries.Len() > c.maxEntries {
+		c.removeElement(c.entries.Back())
+		c.evictions++
+	}
+}
+
+// remove deletes the entries stored against the given keys, returning how many were present
+func (c *cache) remove(keys ...string) int {
+	c.Lock()
+	defer c.Unlock()
+
+	removed := 0
+	for _, key := range keys {
+		if el, found := c.items[key]; found {
+			c.removeElement(el)
+			removed++
+		}
+	}
+	return removed
+}
+
+// valuesIf returns the values of every entry matching the predicate
+func (c *cache) valuesIf(predicate func(key string, value interface{}) bool) []interface{} {
+	c.Lock()
+	defer c.Unlock()
+
+	var values []interface{}
+	for el := c.entries.Front(); el != nil; el = el.Next() {
+		entry := el.Value.(*cacheEntry)
+		if predicate(entry.key, entry.value) {
+			values = append(values, entry.value)
+		}
+	}
+	return values
+}
+
+// removeIf deletes every entry matching the predicate, returning how many were removed
+func (c *cache) removeIf(predicate func(key string, value interface{}) bool) int {
+	c.Lock()
+	defer c.Unlock()
+
+	removed := 0
+	for el := c.entries.Front(); el != nil; {
+		next := el.Next()
+		entry := el.Value.(*cacheEntry)
+		if predicate(entry.key, entry.value) {
+			c.removeElement(el)
+			removed++
+		}
+		el = next
+	}
+	return removed
+}
+
+// flush deletes every entry, returning how many were removed
+func (c *cache) flush() int {
+	c.Lock()
+	defer c.Unlock()
+
+	removed := c.entries.Len()
+	c.entries.Init()
+	c.items = make(map[string]*list.Element)
+	return removed
+}
+
+func (c *cache) stats() CacheStats {
+	c.Lock()
+	defer c.Unlock()
+
+	stats := CacheStats{Size: c.entries.Len(), Hits: c.hits, Misses: c.misses, Evictions: c.evictions}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRatio = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+func (c *cache) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.entries.Len()
+}
+
+func (c *cache) removeElement(el *list.Element) {
+	c.entries.Remove(el)
+	delete(c.items, el.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,121 @@
+package concepts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheGetSet(t *testing.T) {
+	c := newCache(10)
+	c.set("a-key", "a-value", time.Minute)
+
+	value, found := c.get("a-key")
+	assert.True(t, found)
+	assert.Equal(t, "a-value", value)
+
+	_, found = c.get("another-key")
+	assert.False(t, found)
+}
+
+func TestCacheEntriesExpire(t *testing.T) {
+	now := time.Now()
+	c := newCache(10)
+	c.now = func() time.Time { return now }
+	c.set("a-key", "a-value", time.Minute)
+
+	now = now.Add(time.Minute)
+
+	_, found := c.get("a-key")
+	assert.False(t, found)
+	assert.Equal(t, 0, c.len())
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCache(2)
+	c.set("first", 1, time.Minute)
+	c.set("second", 2, time.Minute)
+
+	_, found := c.get("first") // first is now the most recently used
+	assert.True(t, found)
+
+	c.set("third", 3, time.Minute)
+
+	assert.Equal(t, 2, c.len())
+	_, found = c.get("second")
+	assert.False(t, found)
+	_, found = c.get("first")
+	assert.True(t, found)
+	_, found = c.get("third")
+	assert.True(t, found)
+}
+
+func TestCacheSetOverwritesExistingEntry(t *testing.T) {
+	c := newCache(2)
+	c.set("a-key", "a-value", time.Minute)
+	c.set("a-key", "another-value", time.Minute)
+
+	value, found := c.get("a-key")
+	assert.True(t, found)
+	assert.Equal(t, "another-value", value)
+	assert.Equal(t, 1, c.len())
+}
+
+func TestCacheRetainsStaleEntries(t *testing.T) {
+	now := time.Now()
+	c := newCache(10)
+	c.now = func() time.Time { return now }
+	c.setWithStale("a-key", "a-value", time.Minute, time.Hour)
+
+	now = now.Add(time.Minute)
+
+	_, found := c.get("a-key")
+	assert.False(t, found)
+
+	entry, found := c.getEntry("a-key")
+	assert.True(t, found)
+	assert.Equal(t, "a-value", entry.value)
+
+	now = now.Add(time.Hour)
+
+	_, found = c.getEntry("a-key")
+	assert.False(t, found)
+	assert.Equal(t, 0, c.len())
+}
+
+func TestCacheStats(t *testing.T) {
+	c := newCache(1)
+	c.set("first", 1, time.Minute)
+	c.set("second", 2, time.Minute) // evicts first
+
+	c.get("first")
+	c.get("second")
+	c.get("second")
+	c.get("third")
+
+	assert.Equal(t, CacheStats{Size: 1, Hits: 2, Misses: 2, HitRatio: 0.5, Evictions: 1}, c.stats())
+}
+
+func TestCacheRemove(t *testing.T) {
+	c := newCache(10)
+	c.set("first", 1, time.Minute)
+	c.set("second", 2, time.Minute)
+	c.set("third", 3, time.Minute)
+
+	assert.Equal(t, 2, c.remove("first", "third", "fourth"))
+	assert.Equal(t, 1, c.removeIf(func(key string, value interface{}) bool { return value.(int) == 2 }))
+	assert.Equal(t, 0, c.len())
+}
+
+func TestCacheFlush(t *testing.T) {
+	c := newCache(10)
+	c.set("first", 1, time.Minute)
+	c.set("second", 2, time.Minute)
+
+	assert.Equal(t, 2, c.flush())
+	assert.Equal(t, 0, c.len())
+
+	_, found := c.get("first")
+	assert.False(t, found)
+}
@@ -0,0 +1,167 @@
+package concepts
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+)
+
+type cachedConcordances struct {
+	concordances Concordances
+	cache        *cache
+	ttl          time.Duration
+	negativeTTL  time.Duration
+}
+
+// CachedConcordances is a Concordances whose identifiers are held in a Cache
+type CachedConcordances interface {
+	Concordances
+	ConcordancesCache
+}
+
+// NewCachedConcordances wraps the provided Concordances, caching the concorded identifiers of every (authority, id) pair for the given ttl.
+// Pairs which do not concord are remembered for negativeTTL instead, a zero negativeTTL disables caching them.
+// At most maxEntries pairs are held, after which the least recently used are evicted.
+func NewCachedConcordances(concordances Concordances, ttl time.Duration, negativeTTL time.Duration, maxEntries int) CachedConcordances {
+	return &cachedConcordances{concordances: concordances, cache: newCache(maxEntries), ttl: ttl, negativeTTL: negativeTTL}
+}
+
+func (c *cachedConcordances) GetConcordances(ctx context.Context, tid, authority string, ids ...string) (map[string][]Identifier, error) {
+	if err := validateIDs(ids); err != nil {
+		return nil, err
+	}
+
+	identifiers := make(map[string][]Identifier)
+	var missing []string
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if cached, found := c.cache.get(concordancesCacheKey(authority, id)); found {
+			mergeIdentifiers(identifiers, cached.(map[string][]Identifier))
+			continue
+		}
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return identifiers, nil
+	}
+
+	fetched, err := c.concordances.GetConcordances(ctx, tid, authority, missing...)
+	partialErr, partial := err.(*PartialError)
+	if err != nil && !partial {
+		return nil, err
+	}
+
+	for _, id := range missing {
+		if partial && partialErr.Failed[id] != nil {
+			continue
+		}
+		concorded := identifiersFor(authority, id, fetched)
+		if len(concorded) > 0 {
+			c.cache.set(concordancesCacheKey(authority, id), concorded, c.ttl)
+		} else if c.negativeTTL > 0 {
+			c.cache.set(concordancesCacheKey(authority, id), concorded, c.negativeTTL)
+		}
+	}
+
+	mergeIdentifiers(identifiers, fetched)
+	return identifiers, err
+}
+
+func (c *cachedConcordances) Check() fthealth.Check {
+	return c.concordances.Check()
+}
+
+func (c *cachedConcordances) Name() string {
+	return "public-concordances"
+}
+
+func (c *cachedConcordances) Stats() CacheStats {
+	return c.cache.stats()
+}
+
+// Purge removes the entries for the given ids of the authority, or every entry of the authority if no ids are given.
+// With NoAuthority, the ids are purged for every authority, along with any entries concorded to them as canonical uuids.
+func (c *cachedConcordances) Purge(authority string, ids ...string) int {
+	return c.cache.removeIf(purgedBy(authority, ids))
+}
+
+func (c *cachedConcordances) CanonicalUUIDs(authority string, ids ...string) []string {
+	found := make(map[string]bool)
+	for _, value := range c.cache.valuesIf(purgedBy(authority, ids)) {
+		for uuid := range value.(map[string][]Identifier) {
+			found[uuid] = true
+		}
+	}
+
+	uuids := make([]string, 0, len(found))
+	for uuid := range found {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return uuids
+}
+
+func (c *cachedConcordances) Flush() int {
+	return c.cache.flush()
+}
+
+// purgedBy matches the entries purged for the given ids of the authority, as described by Purge
+func purgedBy(authority string, ids []string) func(key string, value interface{}) bool {
+	purge := make(map[string]bool)
+	for _, id := range ids {
+		purge[id] = true
+	}
+
+	return func(key string, value interface{}) bool {
+		keyAuthority, keyID := splitConcordancesCacheKey(key)
+		if authority != NoAuthority {
+			return keyAuthority == authority && (len(ids) == 0 || purge[keyID])
+		}
+		if purge[keyID] {
+			return true
+		}
+		for uuid := range value.(map[string][]Identifier) {
+			if purge[uuid] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func concordancesCacheKey(authority, id string) string {
+	return authority + " " + id
+}
+
+func splitConcordancesCacheKey(key string) (string, string) {
+	parts := strings.SplitN(key, " ", 2)
+	return parts[0], parts[1]
+}
+
+// identifiersFor returns the entries of the identifiers map whose canonical concept is concorded to the given id
+func identifiersFor(authority, id string, identifiers map[string][]Identifier) map[string][]Identifier {
+	concorded := make(map[string][]Identifier)
+	for uuid, ids := range identifiers {
+		for _, identifier := range ids {
+			if identifier.IdentifierValue == id && (authority == NoAuthority || identifier.Authority == authority) {
+				concorded[uuid] = ids
+				break
+			}
+		}
+	}
+	return concorded
+}
+
+// mergeIdentifiers adds the identifiers of each uuid to those already held for it, ids of one concept may be looked up separately.
+// The slices of from are never appended to, as they may be cached.
+func mergeIdentifiers(into map[string][]Identifier, from map[string][]Identifier) {
+	for uuid, ids := range from {
+		into[uuid] = appendMissingIdentifiers(into[uuid], ids...)
+	}
+}
@@ -0,0 +1,215 @@
+package concepts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tmeAuthority = "http://api.ft.com/system/FT-TME"
+
+func TestCachedConcordancesOnlyFetchesMissingIDs(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_first", tmeAuthority, []string{"tme-1", "tme-2"}).Return(map[string][]Identifier{
+		"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}},
+		"uuid-2": {{Authority: tmeAuthority, IdentifierValue: "tme-2"}},
+	}, nil)
+	concordances.On("GetConcordances", "tid_second", tmeAuthority, []string{"tme-3"}).Return(map[string][]Identifier{
+		"uuid-3": {{Authority: tmeAuthority, IdentifierValue: "tme-3"}},
+	}, nil)
+
+	cached := NewCachedConcordances(concordances, time.Minute, 0, 10)
+
+	identifiers, err := cached.GetConcordances(context.Background(), "tid_first", tmeAuthority, "tme-1", "tme-2")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 2)
+
+	identifiers, err = cached.GetConcordances(context.Background(), "tid_second", tmeAuthority, "tme-1", "tme-2", "tme-3")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Identifier{
+		"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}},
+		"uuid-2": {{Authority: tmeAuthority, IdentifierValue: "tme-2"}},
+		"uuid-3": {{Authority: tmeAuthority, IdentifierValue: "tme-3"}},
+	}, identifiers)
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesAreKeyedByAuthority(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesAreKeyedByAuthority", NoAuthority, []string{"uuid-1"}).Return(map[string][]Identifier{
+		"uuid-1": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "uuid-1"}},
+	}, nil).Once()
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesAreKeyedByAuthority", tmeAuthority, []string{"uuid-1"}).Return(map[string][]Identifier{}, nil).Once()
+
+	cached := NewCachedConcordances(concordances, time.Minute, 0, 10)
+
+	identifiers, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesAreKeyedByAuthority", NoAuthority, "uuid-1")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 1)
+
+	identifiers, err = cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesAreKeyedByAuthority", tmeAuthority, "uuid-1")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 0)
+
+	identifiers, err = cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesAreKeyedByAuthority", NoAuthority, "uuid-1")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 1)
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesSharedCanonicalConcept(t *testing.T) {
+	identifiersOfUUID := []Identifier{
+		{Authority: tmeAuthority, IdentifierValue: "tme-1"},
+		{Authority: tmeAuthority, IdentifierValue: "tme-2"},
+	}
+
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesSharedCanonicalConcept", tmeAuthority, []string{"tme-1", "tme-2"}).
+		Return(map[string][]Identifier{"uuid-1": identifiersOfUUID}, nil).Once()
+
+	cached := NewCachedConcordances(concordances, time.Minute, 0, 10)
+
+	_, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesSharedCanonicalConcept", tmeAuthority, "tme-1", "tme-2")
+	assert.NoError(t, err)
+
+	identifiers, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesSharedCanonicalConcept", tmeAuthority, "tme-2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Identifier{"uuid-1": identifiersOfUUID}, identifiers)
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesSiblingOfCachedID(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesSiblingOfCachedID", tmeAuthority, []string{"tme-1"}).
+		Return(map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}}}, nil).Once()
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesSiblingOfCachedID", tmeAuthority, []string{"tme-2"}).
+		Return(map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-2"}}}, nil).Once()
+
+	cached := NewCachedConcordances(concordances, time.Minute, 0, 10)
+
+	_, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesSiblingOfCachedID", tmeAuthority, "tme-1")
+	assert.NoError(t, err)
+
+	identifiers, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesSiblingOfCachedID", tmeAuthority, "tme-1", "tme-2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Identifier{"uuid-1": {
+		{Authority: tmeAuthority, IdentifierValue: "tme-1"},
+		{Authority: tmeAuthority, IdentifierValue: "tme-2"},
+	}}, identifiers)
+
+	identifiers, err = cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesSiblingOfCachedID", tmeAuthority, "tme-1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}}}, identifiers, "the cached identifiers are not modified")
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesDoesNotCacheFailures(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesDoesNotCacheFailures", tmeAuthority, []string{"tme-1"}).
+		Return(map[string][]Identifier{}, errComputerSaysNo).Once()
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesDoesNotCacheFailures", tmeAuthority, []string{"tme-1"}).
+		Return(map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}}}, nil).Once()
+
+	cached := NewCachedConcordances(concordances, time.Minute, 0, 10)
+
+	_, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesDoesNotCacheFailures", tmeAuthority, "tme-1")
+	assert.Equal(t, errComputerSaysNo, err)
+
+	identifiers, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesDoesNotCacheFailures", tmeAuthority, "tme-1")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 1)
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesValidatesIDs(t *testing.T) {
+	cached := NewCachedConcordances(new(mockConcordances), time.Minute, 0, 10)
+
+	_, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesValidatesIDs", NoAuthority)
+	assert.Equal(t, ErrNoConceptsToSearch, err)
+
+	_, err = cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesValidatesIDs", NoAuthority, "")
+	assert.Equal(t, ErrConceptIDsAreEmpty, err)
+}
+
+func TestCachedConcordancesRemembersIDsWhichDoNotConcord(t *testing.T) {
+	now := time.Now()
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesRemembersIDsWhichDoNotConcord", tmeAuthority, []string{"tme-1", "unknown-tme"}).
+		Return(map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}}}, nil).Once()
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesRemembersIDsWhichDoNotConcord", tmeAuthority, []string{"unknown-tme"}).
+		Return(map[string][]Identifier{}, nil).Once()
+
+	cached := NewCachedConcordances(concordances, time.Hour, time.Minute, 10)
+	cached.(*cachedConcordances).cache.now = func() time.Time { return now }
+
+	_, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesRemembersIDsWhichDoNotConcord", tmeAuthority, "tme-1", "unknown-tme")
+	assert.NoError(t, err)
+
+	identifiers, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesRemembersIDsWhichDoNotConcord", tmeAuthority, "tme-1", "unknown-tme")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 1)
+
+	now = now.Add(time.Minute) // the negative entry has expired, but the concorded one has not
+
+	identifiers, err = cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesRemembersIDsWhichDoNotConcord", tmeAuthority, "tme-1", "unknown-tme")
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 1)
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesNegativeCachingDisabled(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesNegativeCachingDisabled", tmeAuthority, []string{"unknown-tme"}).
+		Return(map[string][]Identifier{}, nil).Twice()
+
+	cached := NewCachedConcordances(concordances, time.Hour, 0, 10)
+
+	for i := 0; i < 2; i++ {
+		identifiers, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesNegativeCachingDisabled", tmeAuthority, "unknown-tme")
+		assert.NoError(t, err)
+		assert.Len(t, identifiers, 0)
+	}
+
+	concordances.AssertExpectations(t)
+}
+
+func TestCachedConcordancesPurge(t *testing.T) {
+	uppAuthority := "http://api.ft.com/system/UPP"
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesPurge", tmeAuthority, []string{"tme-1", "tme-2", "tme-3"}).Return(map[string][]Identifier{
+		"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}},
+		"uuid-2": {{Authority: tmeAuthority, IdentifierValue: "tme-2"}},
+		"uuid-3": {{Authority: tmeAuthority, IdentifierValue: "tme-3"}},
+	}, nil)
+	concordances.On("GetConcordances", "tid_TestCachedConcordancesPurge", NoAuthority, []string{"uuid-4"}).Return(map[string][]Identifier{
+		"uuid-4": {{Authority: uppAuthority, IdentifierValue: "uuid-4"}},
+	}, nil)
+
+	cached := NewCachedConcordances(concordances, time.Minute, 0, 10)
+
+	_, err := cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesPurge", tmeAuthority, "tme-1", "tme-2", "tme-3")
+	assert.NoError(t, err)
+	_, err = cached.GetConcordances(context.Background(), "tid_TestCachedConcordancesPurge", NoAuthority, "uuid-4")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"uuid-1"}, cached.CanonicalUUIDs(tmeAuthority, "tme-1"))
+	assert.Equal(t, []string{"uuid-1", "uuid-2", "uuid-3"}, cached.CanonicalUUIDs(tmeAuthority))
+	assert.Equal(t, []string{"uuid-2", "uuid-4"}, cached.CanonicalUUIDs(NoAuthority, "uuid-2", "uuid-4"))
+	assert.Equal(t, 4, cached.Stats().Size)
+
+	assert.Equal(t, 1, cached.Purge(tmeAuthority, "tme-1"))
+	assert.Equal(t, 0, cached.Purge(tmeAuthority, "uuid-4"))
+	assert.Equal(t, 2, cached.Purge(NoAuthority, "uuid-2", "uuid-4")) // by canonical uuid, and by id under any authority
+	assert.Equal(t, 1, cached.Purge(tmeAuthority))
+	assert.Equal(t, 0, cached.Stats().Size)
+
+	assert.Equal(t, "public-concordances", cached.Name())
+}
@@ -0,0 +1,177 @@
+package concepts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+)
+
+type cachedSearch struct {
+	search       Search
+	cache        *cache
+	ttl          time.Duration
+	staleTTL     time.Duration
+	refreshAhead time.Duration
+
+	refreshLock sync.Mutex
+	refreshing  map[string]bool
+	refreshes   sync.WaitGroup
+}
+
+// CachedSearch is a Search whose concepts are held in a Cache
+type CachedSearch interface {
+	Search
+	Cache
+}
+
+// NewCachedSearch wraps the provided Search, caching every returned concept by uuid for the given ttl.
+// Expired concepts are kept for a further staleTTL, and served marked as Stale if the wrapped Search fails.
+// Concepts requested within refreshAhead of their expiry are served from cache and refreshed in the background.
+// At most maxEntries concepts are held, after which the least recently used are evicted.
+func NewCachedSearch(search Search, ttl time.Duration, staleTTL time.Duration, refreshAhead time.Duration, maxEntries int) CachedSearch {
+	return &cachedSearch{
+		search:       search,
+		cache:        newCache(maxEntries),
+		ttl:          ttl,
+		staleTTL:     staleTTL,
+		refreshAhead: refreshAhead,
+		refreshing:   make(map[string]bool),
+	}
+}
+
+func (c *cachedSearch) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error) {
+	if err := validateIDs(uuids); err != nil {
+		return nil, err
+	}
+
+	concepts := make(map[string]Concept)
+	stale := make(map[string]Concept)
+	var missing []string
+	var expiring []string
+
+	now := c.cache.now()
+	for _, uuid := range uuids {
+		if uuid == "" {
+			continue
+		}
+
+		entry, found := c.cache.getEntry(uuid)
+		switch {
+		case !found:
+			missing = append(missing, uuid)
+		case !now.Before(entry.expiresAt):
+			stale[uuid] = entry.value.(Concept)
+			missing = append(missing, uuid)
+		default:
+			concepts[uuid] = entry.value.(Concept)
+			if c.refreshAhead > 0 && !now.Before(entry.expiresAt.Add(-c.refreshAhead)) {
+				expiring = append(expiring, uuid)
+			}
+		}
+	}
+
+	c.refresh(ctx, tid, expiring)
+
+	if len(missing) == 0 {
+		return concepts, nil
+	}
+
+	fetched, err := c.search.ByIDs(ctx, tid, missing...)
+	failed := make(map[string]error)
+	switch e := err.(type) {
+	case nil:
+	case *PartialError:
+		failed = e.Failed
+	default:
+		for _, uuid := range missing {
+			failed[uuid] = err
+		}
+	}
+
+	for uuid, concept := range fetched {
+		c.cache.setWithStale(uuid, concept, c.ttl, c.staleTTL)
+		concepts[uuid] = concept
+	}
+
+	unresolved := make(map[string]error)
+	for uuid, failure := range failed {
+		if concept, found := stale[uuid]; found {
+			concept.Stale = true
+			concepts[uuid] = concept
+			continue
+		}
+		unresolved[uuid] = failure
+	}
+
+	if len(unresolved) == 0 {
+		return concepts, nil
+	}
+	if _, partial := err.(*PartialError); !partial && len(concepts) == 0 {
+		return nil, err
+	}
+	// the concepts resolved from cache are still returned when the wrapped Search fails completely
+	return concepts, &PartialError{Failed: unresolved}
+}
+
+// refresh fetches the given uuids in the background, skipping any which are already being refreshed.
+// The refresh is not cancelled along with the request which triggered it.
+func (c *cachedSearch) refresh(ctx context.Context, tid string, uuids []string) {
+	c.refreshLock.Lock()
+	var toRefresh []string
+	for _, uuid := range uuids {
+		if !c.refreshing[uuid] {
+			c.refreshing[uuid] = true
+			toRefresh = append(toRefresh, uuid)
+		}
+	}
+	c.refreshLock.Unlock()
+
+	if len(toRefresh) == 0 {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	c.refreshes.Add(1)
+	go func() {
+		defer c.refreshes.Done()
+
+		fetched, err := c.search.ByIDs(ctx, tid, toRefresh...)
+		if err == nil { // on failure the existing entries are left to expire, and will be fetched again on demand
+			for uuid, concept := range fetched {
+				c.cache.setWithStale(uuid, concept, c.ttl, c.staleTTL)
+			}
+		}
+
+		c.refreshLock.Lock()
+		for _, uuid := range toRefresh {
+			delete(c.refreshing, uuid)
+		}
+		c.refreshLock.Unlock()
+	}()
+}
+
+func (c *cachedSearch) Check() fthealth.Check {
+	return c.search.Check()
+}
+
+func (c *cachedSearch) Name() string {
+	return "concept-search"
+}
+
+func (c *cachedSearch) Stats() CacheStats {
+	return c.cache.stats()
+}
+
+// Purge removes the given concept uuids, concepts are not cached by any other authority
+func (c *cachedSearch) Purge(authority string, uuids ...string) int {
+	if authority != NoAuthority {
+		return 0
+	}
+	return c.cache.remove(uuids...)
+}
+
+func (c *cachedSearch) Flush() int {
+	return c.cache.flush()
+}
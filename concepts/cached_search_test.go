@@ -0,0 +1,238 @@
+package concepts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errComputerSaysNo = errors.New("computer says no")
+
+func TestCachedSearchOnlyFetchesMissingUUIDs(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_first", []string{"uuid-1", "uuid-2"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+		"uuid-2": {ID: "http://www.ft.com/thing/uuid-2", PrefLabel: "Second"},
+	}, nil)
+	search.On("ByIDs", "tid_second", []string{"uuid-3"}).Return(map[string]Concept{
+		"uuid-3": {ID: "http://www.ft.com/thing/uuid-3", PrefLabel: "Third"},
+	}, nil)
+
+	cached := NewCachedSearch(search, time.Minute, 0, 0, 10)
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_first", "uuid-1", "uuid-2")
+	assert.NoError(t, err)
+	assert.Len(t, concepts, 2)
+
+	concepts, err = cached.ByIDs(context.Background(), "tid_second", "uuid-1", "uuid-2", "uuid-3")
+	assert.NoError(t, err)
+	assert.Len(t, concepts, 3)
+	assert.Equal(t, "Third", concepts["uuid-3"].PrefLabel)
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchAllCached(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchAllCached", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, nil).Once()
+
+	cached := NewCachedSearch(search, time.Minute, 0, 0, 10)
+
+	for i := 0; i < 3; i++ {
+		concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchAllCached", "uuid-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "First", concepts["uuid-1"].PrefLabel)
+	}
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchDoesNotCacheFailures(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchDoesNotCacheFailures", []string{"uuid-1"}).Return(map[string]Concept{}, errComputerSaysNo).Once()
+	search.On("ByIDs", "tid_TestCachedSearchDoesNotCacheFailures", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, nil).Once()
+
+	cached := NewCachedSearch(search, time.Minute, 0, 0, 10)
+
+	_, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchDoesNotCacheFailures", "uuid-1")
+	assert.Equal(t, errComputerSaysNo, err)
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchDoesNotCacheFailures", "uuid-1")
+	assert.NoError(t, err)
+	assert.Len(t, concepts, 1)
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchValidatesIDs(t *testing.T) {
+	cached := NewCachedSearch(new(mockSearch), time.Minute, 0, 0, 10)
+
+	_, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchValidatesIDs")
+	assert.Equal(t, ErrNoConceptsToSearch, err)
+
+	_, err = cached.ByIDs(context.Background(), "tid_TestCachedSearchValidatesIDs", "", "")
+	assert.Equal(t, ErrConceptIDsAreEmpty, err)
+}
+
+func TestCachedSearchServesStaleConceptsIfSearchFails(t *testing.T) {
+	now := time.Now()
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchServesStaleConceptsIfSearchFails", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, nil).Once()
+	search.On("ByIDs", "tid_TestCachedSearchServesStaleConceptsIfSearchFails", []string{"uuid-1"}).Return(map[string]Concept{}, errComputerSaysNo).Twice()
+
+	cached := NewCachedSearch(search, time.Minute, time.Hour, 0, 10)
+	cached.(*cachedSearch).cache.now = func() time.Time { return now }
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchServesStaleConceptsIfSearchFails", "uuid-1")
+	assert.NoError(t, err)
+	assert.False(t, concepts["uuid-1"].Stale)
+
+	now = now.Add(2 * time.Minute)
+
+	concepts, err = cached.ByIDs(context.Background(), "tid_TestCachedSearchServesStaleConceptsIfSearchFails", "uuid-1")
+	assert.NoError(t, err)
+	assert.True(t, concepts["uuid-1"].Stale)
+	assert.Equal(t, "First", concepts["uuid-1"].PrefLabel)
+
+	now = now.Add(time.Hour)
+
+	_, err = cached.ByIDs(context.Background(), "tid_TestCachedSearchServesStaleConceptsIfSearchFails", "uuid-1")
+	assert.Equal(t, errComputerSaysNo, err)
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchFailsIfNotAllMissingConceptsAreStale(t *testing.T) {
+	now := time.Now()
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchFailsIfNotAllMissingConceptsAreStale", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, nil).Once()
+	search.On("ByIDs", "tid_TestCachedSearchFailsIfNotAllMissingConceptsAreStale", []string{"uuid-1", "uuid-2"}).Return(map[string]Concept{}, errComputerSaysNo).Once()
+
+	cached := NewCachedSearch(search, time.Minute, time.Hour, 0, 10)
+	cached.(*cachedSearch).cache.now = func() time.Time { return now }
+
+	_, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchFailsIfNotAllMissingConceptsAreStale", "uuid-1")
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchFailsIfNotAllMissingConceptsAreStale", "uuid-1", "uuid-2")
+	assert.Equal(t, &PartialError{Failed: map[string]error{"uuid-2": errComputerSaysNo}}, err)
+	assert.Equal(t, map[string]Concept{"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First", Stale: true}}, concepts)
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchKeepsCachedConceptsIfSearchFails(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchKeepsCachedConceptsIfSearchFails", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, nil).Once()
+	search.On("ByIDs", "tid_TestCachedSearchKeepsCachedConceptsIfSearchFails", []string{"uuid-2"}).Return(map[string]Concept(nil), errComputerSaysNo).Twice()
+
+	cached := NewCachedSearch(search, time.Minute, 0, 0, 10)
+
+	_, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchKeepsCachedConceptsIfSearchFails", "uuid-1")
+	assert.NoError(t, err)
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchKeepsCachedConceptsIfSearchFails", "uuid-1", "uuid-2")
+	assert.Equal(t, &PartialError{Failed: map[string]error{"uuid-2": errComputerSaysNo}}, err)
+	assert.Equal(t, map[string]Concept{"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"}}, concepts)
+
+	concepts, err = cached.ByIDs(context.Background(), "tid_TestCachedSearchKeepsCachedConceptsIfSearchFails", "uuid-2")
+	assert.Equal(t, errComputerSaysNo, err)
+	assert.Nil(t, concepts)
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchRefreshesConceptsNearExpiry(t *testing.T) {
+	now := time.Now()
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchRefreshesConceptsNearExpiry", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, nil).Once()
+	search.On("ByIDs", "tid_TestCachedSearchRefreshesConceptsNearExpiry", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "Refreshed"},
+	}, nil).Once()
+
+	cached := NewCachedSearch(search, time.Minute, 0, 10*time.Second, 10)
+	cached.(*cachedSearch).cache.now = func() time.Time { return now }
+
+	_, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchRefreshesConceptsNearExpiry", "uuid-1")
+	assert.NoError(t, err)
+
+	now = now.Add(55 * time.Second)
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchRefreshesConceptsNearExpiry", "uuid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "First", concepts["uuid-1"].PrefLabel)
+
+	cached.(*cachedSearch).refreshes.Wait()
+
+	concepts, err = cached.ByIDs(context.Background(), "tid_TestCachedSearchRefreshesConceptsNearExpiry", "uuid-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Refreshed", concepts["uuid-1"].PrefLabel)
+
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchPurge(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchPurge", []string{"uuid-1", "uuid-2"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+		"uuid-2": {ID: "http://www.ft.com/thing/uuid-2", PrefLabel: "Second"},
+	}, nil).Once()
+	search.On("ByIDs", "tid_TestCachedSearchPurge", []string{"uuid-1"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First Fixed"},
+	}, nil).Once()
+
+	cached := NewCachedSearch(search, time.Minute, 0, 0, 10)
+
+	_, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchPurge", "uuid-1", "uuid-2")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, cached.Purge(tmeAuthority, "uuid-1"))
+	assert.Equal(t, 1, cached.Purge(NoAuthority, "uuid-1"))
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchPurge", "uuid-1", "uuid-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "First Fixed", concepts["uuid-1"].PrefLabel)
+	assert.Equal(t, "Second", concepts["uuid-2"].PrefLabel)
+
+	assert.Equal(t, "concept-search", cached.Name())
+	assert.Equal(t, CacheStats{Size: 2, Hits: 1, Misses: 3, HitRatio: 0.25}, cached.Stats())
+
+	assert.Equal(t, 2, cached.Flush())
+	search.AssertExpectations(t)
+}
+
+func TestCachedSearchPartialFailure(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestCachedSearchPartialFailure", []string{"uuid-1", "uuid-2"}).Return(map[string]Concept{
+		"uuid-1": {ID: "http://www.ft.com/thing/uuid-1", PrefLabel: "First"},
+	}, &PartialError{Failed: map[string]error{"uuid-2": errComputerSaysNo}}).Once()
+
+	cached := NewCachedSearch(search, time.Minute, 0, 0, 10)
+
+	concepts, err := cached.ByIDs(context.Background(), "tid_TestCachedSearchPartialFailure", "uuid-1", "uuid-2")
+	assert.Equal(t, &PartialError{Failed: map[string]error{"uuid-2": errComputerSaysNo}}, err)
+	assert.Len(t, concepts, 1)
+
+	concepts, err = cached.ByIDs(context.Background(), "tid_TestCachedSearchPartialFailure", "uuid-1")
+	assert.NoError(t, err)
+	assert.Len(t, concepts, 1)
+
+	search.AssertExpectations(t)
+}
@@ -0,0 +1,281 @@
+package concepts
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+	log "github.com/Financial-Times/go-logger"
+)
+
+var errFlightAborted = errors.New("shared upstream lookup was aborted")
+
+// flight is an upstream lookup of a single key, shared by every caller requesting the key while it is in progress
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	call  *flightCall
+}
+
+// flightCall is a fetch of the keys of several flights, cancelled once no caller is waiting on any of them
+type flightCall struct {
+	cancel  context.CancelFunc
+	flights []string
+	waiters int
+}
+
+type flightGroup struct {
+	sync.Mutex
+	flights map[string]*flight
+	// timeout bounds each shared fetch, which outlives the caller that started it while others wait on it
+	timeout time.Duration
+}
+
+func newFlightGroup(timeout time.Duration) *flightGroup {
+	return &flightGroup{flights: make(map[string]*flight), timeout: timeout}
+}
+
+// do resolves every key, starting a fetch of the keys which are not already in flight and waiting on every flight
+// until the context is done. A fetch is not cancelled with the context of the caller which started it, but once
+// no caller is left waiting on it. fetch returns the result of each key it fetched, keys with neither a value nor an error are omitted.
+func (g *flightGroup) do(ctx context.Context, keys []string, fetch func(ctx context.Context, keys []string) func(key string) (interface{}, error)) (map[string]interface{}, map[string]error) {
+	fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	flights, owned, calls := g.join(keys, cancel)
+	defer g.leave(calls)
+	if len(owned) > 0 {
+		go g.fetch(fetchCtx, owned, fetch)
+	} else {
+		cancel()
+	}
+
+	values := make(map[string]interface{})
+	failed := make(map[string]error)
+	for key, f := range flights {
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			failed[key] = ctx.Err()
+			continue
+		}
+
+		if f.err != nil {
+			failed[key] = f.err
+		} else if f.value != nil {
+			values[key] = f.value
+		}
+	}
+	return values, failed
+}
+
+// join returns the flights for all keys, the subset of new flights the caller must fetch, and the calls the caller now waits on
+func (g *flightGroup) join(keys []string, cancel context.CancelFunc) (map[string]*flight, map[string]*flight, map[*flightCall]bool) {
+	g.Lock()
+	defer g.Unlock()
+
+	flights := make(map[string]*flight)
+	owned := make(map[string]*flight)
+	calls := make(map[*flightCall]bool)
+	call := &flightCall{cancel: cancel}
+	for _, key := range keys {
+		if _, found := flights[key]; found {
+			continue
+		}
+		f, found := g.flights[key]
+		if !found {
+			f = &flight{done: make(chan struct{}), call: call}
+			g.flights[key] = f
+			owned[key] = f
+			call.flights = append(call.flights, key)
+		}
+		flights[key] = f
+		if !calls[f.call] {
+			calls[f.call] = true
+			f.call.waiters++
+		}
+	}
+	return flights, owned, calls
+}
+
+// leave stops waiting on the calls, cancelling those nobody else waits on.
+// Their flights are forgotten straight away, so later callers start a new fetch instead of joining a cancelled one.
+func (g *flightGroup) leave(calls map[*flightCall]bool) {
+	g.Lock()
+	defer g.Unlock()
+
+	for call := range calls {
+		call.waiters--
+		if call.waiters > 0 {
+			continue
+		}
+		call.cancel()
+		for _, key := range call.flights {
+			if f, found := g.flights[key]; found && f.call == call {
+				delete(g.flights, key)
+			}
+		}
+	}
+}
+
+func (g *flightGroup) fetch(ctx context.Context, owned map[string]*flight, fetch func(ctx context.Context, keys []string) func(key string) (interface{}, error)) {
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	result := func(string) (interface{}, error) { return nil, errFlightAborted }
+	defer func() { // completes the flights even if fetch panics, so no callers are left waiting
+		if r := recover(); r != nil {
+			log.Errorf("Shared upstream lookup panicked: %v", r)
+		}
+
+		g.Lock()
+		for key, f := range owned {
+			if g.flights[key] == f {
+				delete(g.flights, key)
+			}
+		}
+		g.Unlock()
+
+		for key, f := range owned {
+			f.value, f.err = result(key)
+			close(f.done)
+		}
+	}()
+
+	keys := make([]string, 0, len(owned))
+	for key := range owned {
+		keys = append(keys, key)
+	}
+	result = fetch(ctx, keys)
+}
+
+// collectFailures returns nil if no ids failed, the error of the first id if they all failed, or a PartialError otherwise
+func collectFailures(ids []string, failed map[string]error) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(failed) == len(ids) {
+		return failed[ids[0]]
+	}
+	return &PartialError{Failed: failed}
+}
+
+type coalescingSearch struct {
+	search  Search
+	flights *flightGroup
+}
+
+// NewCoalescingSearch wraps the provided Search, so concurrent lookups of the same uuids share a single upstream request.
+// The shared request is cancelled once every caller waiting on it has given up, and is bounded by timeout, zero disables the timeout.
+func NewCoalescingSearch(search Search, timeout time.Duration) Search {
+	return &coalescingSearch{search: search, flights: newFlightGroup(timeout)}
+}
+
+func (c *coalescingSearch) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error) {
+	if err := validateIDs(uuids); err != nil {
+		return nil, err
+	}
+
+	uuids = uniqueNonEmpty(uuids)
+	values, failed := c.flights.do(ctx, uuids, func(ctx context.Context, toFetch []string) func(string) (interface{}, error) {
+		fetched, err := c.search.ByIDs(ctx, tid, toFetch...)
+		return func(uuid string) (interface{}, error) {
+			if concept, found := fetched[uuid]; found {
+				return concept, nil
+			}
+			return nil, FailureFor(uuid, err)
+		}
+	})
+
+	err := collectFailures(uuids, failed)
+	if _, partial := err.(*PartialError); err != nil && !partial {
+		return nil, err
+	}
+
+	concepts := make(map[string]Concept)
+	for uuid, concept := range values {
+		concepts[uuid] = concept.(Concept)
+	}
+	return concepts, err
+}
+
+func (c *coalescingSearch) Check() fthealth.Check {
+	return c.search.Check()
+}
+
+type coalescingConcordances struct {
+	concordances Concordances
+	flights      *flightGroup
+}
+
+// NewCoalescingConcordances wraps the provided Concordances, so concurrent lookups of the same (authority, id) pairs share a single upstream request.
+// The shared request is cancelled once every caller waiting on it has given up, and is bounded by timeout, zero disables the timeout.
+func NewCoalescingConcordances(concordances Concordances, timeout time.Duration) Concordances {
+	return &coalescingConcordances{concordances: concordances, flights: newFlightGroup(timeout)}
+}
+
+func (c *coalescingConcordances) GetConcordances(ctx context.Context, tid, authority string, ids ...string) (map[string][]Identifier, error) {
+	if err := validateIDs(ids); err != nil {
+		return nil, err
+	}
+
+	ids = uniqueNonEmpty(ids)
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, concordancesCacheKey(authority, id))
+	}
+
+	values, failed := c.flights.do(ctx, keys, func(ctx context.Context, toFetch []string) func(string) (interface{}, error) {
+		fetchIDs := make([]string, 0, len(toFetch))
+		for _, key := range toFetch {
+			_, id := splitConcordancesCacheKey(key)
+			fetchIDs = append(fetchIDs, id)
+		}
+
+		fetched, err := c.concordances.GetConcordances(ctx, tid, authority, fetchIDs...)
+		return func(key string) (interface{}, error) {
+			_, id := splitConcordancesCacheKey(key)
+			if failure := FailureFor(id, err); failure != nil {
+				return nil, failure
+			}
+			return identifiersFor(authority, id, fetched), nil
+		}
+	})
+
+	failedIDs := make(map[string]error)
+	for key, err := range failed {
+		_, id := splitConcordancesCacheKey(key)
+		failedIDs[id] = err
+	}
+
+	err := collectFailures(ids, failedIDs)
+	if _, partial := err.(*PartialError); err != nil && !partial {
+		return nil, err
+	}
+
+	identifiers := make(map[string][]Identifier)
+	for _, concorded := range values {
+		mergeIdentifiers(identifiers, concorded.(map[string][]Identifier))
+	}
+	return identifiers, err
+}
+
+func (c *coalescingConcordances) Check() fthealth.Check {
+	return c.concordances.Check()
+}
+
+func uniqueNonEmpty(ids []string) []string {
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
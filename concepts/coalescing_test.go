@@ -0,0 +1,318 @@
+package concepts
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingSearch records every lookup, and holds the first until released, failing it if its context was done meanwhile
+type blockingSearch struct {
+	sync.Mutex
+	calls     [][]string
+	started   chan struct{}
+	release   chan struct{}
+	failFirst bool
+}
+
+func newBlockingSearch() *blockingSearch {
+	return &blockingSearch{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (b *blockingSearch) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error) {
+	sort.Strings(uuids)
+	b.Lock()
+	b.calls = append(b.calls, uuids)
+	first := len(b.calls) == 1
+	b.Unlock()
+
+	b.started <- struct{}{}
+	if first {
+		<-b.release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if b.failFirst {
+			return nil, errComputerSaysNo
+		}
+	}
+
+	concepts := make(map[string]Concept)
+	for _, uuid := range uuids {
+		if uuid != "not-found" {
+			concepts[uuid] = Concept{ID: ftIDPrefix + uuid}
+		}
+	}
+	return concepts, nil
+}
+
+func (b *blockingSearch) Check() fthealth.Check {
+	return fthealth.Check{}
+}
+
+func TestCoalescingSearchSharesInFlightLookups(t *testing.T) {
+	upstream := newBlockingSearch()
+	search := NewCoalescingSearch(upstream, 0)
+
+	var wg sync.WaitGroup
+	var first, second map[string]Concept
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		first, _ = search.ByIDs(context.Background(), "tid_first", "uuid-1", "uuid-2", "not-found")
+	}()
+	<-upstream.started
+
+	go func() {
+		defer wg.Done()
+		second, _ = search.ByIDs(context.Background(), "tid_second", "uuid-2", "uuid-3", "not-found")
+	}()
+	<-upstream.started
+
+	close(upstream.release)
+	wg.Wait()
+
+	assert.Equal(t, [][]string{{"not-found", "uuid-1", "uuid-2"}, {"uuid-3"}}, upstream.calls)
+	assert.Equal(t, map[string]Concept{"uuid-1": {ID: ftIDPrefix + "uuid-1"}, "uuid-2": {ID: ftIDPrefix + "uuid-2"}}, first)
+	assert.Equal(t, map[string]Concept{"uuid-2": {ID: ftIDPrefix + "uuid-2"}, "uuid-3": {ID: ftIDPrefix + "uuid-3"}}, second)
+}
+
+func TestCoalescingSearchSharesFailures(t *testing.T) {
+	upstream := newBlockingSearch()
+	upstream.failFirst = true
+	search := NewCoalescingSearch(upstream, 0)
+
+	var wg sync.WaitGroup
+	var firstErr, secondErr error
+	var second map[string]Concept
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, firstErr = search.ByIDs(context.Background(), "tid_first", "uuid-1")
+	}()
+	<-upstream.started
+
+	go func() {
+		defer wg.Done()
+		second, secondErr = search.ByIDs(context.Background(), "tid_second", "uuid-1", "uuid-2")
+	}()
+
+	select {
+	case <-upstream.started:
+	case <-time.After(time.Second):
+		require.Fail(t, "second lookup was not started")
+	}
+	close(upstream.release)
+	wg.Wait()
+
+	assert.Equal(t, errComputerSaysNo, firstErr)
+	assert.Equal(t, &PartialError{Failed: map[string]error{"uuid-1": errComputerSaysNo}}, secondErr)
+	assert.Equal(t, map[string]Concept{"uuid-2": {ID: ftIDPrefix + "uuid-2"}}, second)
+}
+
+func TestCoalescingSearchValidatesIDs(t *testing.T) {
+	search := NewCoalescingSearch(newBlockingSearch(), 0)
+
+	_, err := search.ByIDs(context.Background(), "tid_TestCoalescingSearchValidatesIDs")
+	assert.Equal(t, ErrNoConceptsToSearch, err)
+}
+
+func TestCoalescingConcordances(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCoalescingConcordances", tmeAuthority, []string{"tme-1", "tme-2"}).Return(map[string][]Identifier{
+		"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}},
+	}, &PartialError{Failed: map[string]error{"tme-2": errComputerSaysNo}})
+
+	coalescing := NewCoalescingConcordances(concordances, 0)
+	identifiers, err := coalescing.GetConcordances(context.Background(), "tid_TestCoalescingConcordances", tmeAuthority, "tme-1", "tme-2", "tme-1")
+
+	assert.Equal(t, &PartialError{Failed: map[string]error{"tme-2": errComputerSaysNo}}, err)
+	assert.Equal(t, map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}}}, identifiers)
+	concordances.AssertExpectations(t)
+}
+
+func TestCoalescingConcordancesFails(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestCoalescingConcordancesFails", NoAuthority, []string{"uuid-1"}).
+		Return(map[string][]Identifier{}, errComputerSaysNo)
+
+	coalescing := NewCoalescingConcordances(concordances, 0)
+	identifiers, err := coalescing.GetConcordances(context.Background(), "tid_TestCoalescingConcordancesFails", NoAuthority, "uuid-1", "")
+
+	assert.Equal(t, errComputerSaysNo, err)
+	assert.Nil(t, identifiers)
+	concordances.AssertExpectations(t)
+}
+
+func TestCoalescingSearchWaitsUntilContextDone(t *testing.T) {
+	upstream := newBlockingSearch()
+	search := NewCoalescingSearch(upstream, 0)
+	defer close(upstream.release)
+
+	go search.ByIDs(context.Background(), "tid_first", "uuid-1")
+	<-upstream.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := search.ByIDs(ctx, "tid_second", "uuid-1")
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// blockingConcordances concords every id to uuid-1, holding the first lookup until released
+type blockingConcordances struct {
+	sync.Mutex
+	calls   int
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingConcordances() *blockingConcordances {
+	return &blockingConcordances{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (b *blockingConcordances) GetConcordances(ctx context.Context, tid, authority string, ids ...string) (map[string][]Identifier, error) {
+	b.Lock()
+	b.calls++
+	first := b.calls == 1
+	b.Unlock()
+
+	b.started <- struct{}{}
+	if first {
+		<-b.release
+	}
+
+	identifiers := make(map[string][]Identifier)
+	for _, id := range ids {
+		identifiers["uuid-1"] = append(identifiers["uuid-1"], Identifier{Authority: authority, IdentifierValue: id})
+	}
+	return identifiers, nil
+}
+
+func (b *blockingConcordances) Check() fthealth.Check {
+	return fthealth.Check{}
+}
+
+func TestCoalescingConcordancesMergesIdentifiersOfFlights(t *testing.T) {
+	upstream := newBlockingConcordances()
+	coalescing := NewCoalescingConcordances(upstream, 0)
+
+	go coalescing.GetConcordances(context.Background(), "tid_first", tmeAuthority, "tme-1")
+	<-upstream.started
+
+	go func() {
+		<-upstream.started // the second lookup only fetches tme-2, as tme-1 is in flight
+		close(upstream.release)
+	}()
+
+	identifiers, err := coalescing.GetConcordances(context.Background(), "tid_second", tmeAuthority, "tme-1", "tme-2")
+
+	assert.NoError(t, err)
+	require.Len(t, identifiers, 1)
+	assert.ElementsMatch(t, []Identifier{
+		{Authority: tmeAuthority, IdentifierValue: "tme-1"},
+		{Authority: tmeAuthority, IdentifierValue: "tme-2"},
+	}, identifiers["uuid-1"])
+}
+
+func TestCoalescingSearchOutlivesCancelledOwner(t *testing.T) {
+	upstream := newBlockingSearch()
+	search := NewCoalescingSearch(upstream, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	owned := make(chan error)
+	go func() {
+		_, err := search.ByIDs(ctx, "tid_owner", "uuid-1")
+		owned <- err
+	}()
+	<-upstream.started
+
+	waited := make(chan map[string]Concept)
+	go func() {
+		concepts, err := search.ByIDs(context.Background(), "tid_waiter", "uuid-1", "uuid-2")
+		assert.NoError(t, err)
+		waited <- concepts
+	}()
+	<-upstream.started // the waiter only fetches uuid-2 once it has joined the flight of uuid-1
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-owned)
+
+	close(upstream.release)
+
+	assert.Equal(t, map[string]Concept{"uuid-1": {ID: ftIDPrefix + "uuid-1"}, "uuid-2": {ID: ftIDPrefix + "uuid-2"}}, <-waited)
+	assert.Equal(t, [][]string{{"uuid-1"}, {"uuid-2"}}, upstream.calls)
+}
+
+func TestCoalescingSearchCancelsAbandonedLookup(t *testing.T) {
+	upstream := newContextSearch()
+	search := NewCoalescingSearch(upstream, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := make(chan error)
+	go func() {
+		_, err := search.ByIDs(ctx, "tid_first", "uuid-1")
+		abandoned <- err
+	}()
+	<-upstream.started
+
+	waiterCtx, waiterCancel := context.WithCancel(context.Background())
+	waited := make(chan error)
+	go func() {
+		_, err := search.ByIDs(waiterCtx, "tid_waiter", "uuid-1", "uuid-2")
+		waited <- err
+	}()
+	<-upstream.started // the waiter only fetches uuid-2 once it has joined the flight of uuid-1
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-abandoned)
+	select {
+	case err := <-upstream.stopped:
+		t.Fatalf("lookup stopped while a caller still waited on it: %v", err)
+	default:
+	}
+
+	waiterCancel()
+	assert.Equal(t, context.Canceled, <-waited)
+	assert.Equal(t, context.Canceled, <-upstream.stopped)
+	assert.Equal(t, context.Canceled, <-upstream.stopped)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	go search.ByIDs(ctx, "tid_later", "uuid-1")
+	<-upstream.started // a later caller starts a new lookup, rather than joining the cancelled one
+}
+
+// contextSearch finds nothing, only returning once its context is done
+type contextSearch struct {
+	started chan struct{}
+	stopped chan error
+}
+
+func newContextSearch() *contextSearch {
+	return &contextSearch{started: make(chan struct{}, 10), stopped: make(chan error, 10)}
+}
+
+func (c *contextSearch) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error) {
+	c.started <- struct{}{}
+	<-ctx.Done()
+	c.stopped <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func (c *contextSearch) Check() fthealth.Check {
+	return fthealth.Check{}
+}
+
+func TestCoalescingSearchTimesOutSharedLookup(t *testing.T) {
+	search := NewCoalescingSearch(newContextSearch(), 10*time.Millisecond)
+
+	_, err := search.ByIDs(context.Background(), "tid_TestCoalescingSearchTimesOutSharedLookup", "uuid-1")
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -1,9 +1,11 @@
 package concepts
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 )
@@ -16,29 +18,80 @@ const (
 )
 
 type Concordances interface {
-	GetConcordances(tid, authority string, ids ...string) (map[string][]Identifier, error)
+	GetConcordances(ctx context.Context, tid, authority string, ids ...string) (map[string][]Identifier, error)
 	Check() fthealth.Check
 }
 
 type publicConcordancesAPI struct {
-	client *http.Client
-	uri    string
+	httpAPI
 }
 
 type publicConcordancesResponse struct {
 	Concordances []Concordance `json:"concordances"`
 }
 
-func NewConcordances(client *http.Client, uri string) Concordances {
-	return &publicConcordancesAPI{client: client, uri: uri}
+func NewConcordances(client *http.Client, uri string, opts ...Option) Concordances {
+	return &publicConcordancesAPI{httpAPI: newHTTPAPI("public-concordances-api", client, uri, opts)}
 }
 
-func (c *publicConcordancesAPI) GetConcordances(tid, authority string, ids ...string) (map[string][]Identifier, error) {
+func (c *publicConcordancesAPI) GetConcordances(ctx context.Context, tid, authority string, ids ...string) (map[string][]Identifier, error) {
 	if err := validateIDs(ids); err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", c.uri+"/concordances", nil)
+	identifiers := make(map[string][]Identifier)
+	var mutex sync.Mutex
+	err := c.fetchInChunks(ids, func(chunk []string) error {
+		fetched, err := c.fetchConcordances(ctx, tid, authority, chunk)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for uuid, concorded := range fetched {
+			identifiers[uuid] = appendMissingIdentifiers(identifiers[uuid], concorded...)
+		}
+		return nil
+	})
+
+	if _, partial := err.(*PartialError); err != nil && !partial {
+		return nil, err
+	}
+	return identifiers, err
+}
+
+// GetConcordancesForAuthorities concords the ids of several authorities, making one call per authority in parallel.
+// The identifiers returned by every call are merged, and returned along with the error of each authority whose call failed.
+func GetConcordancesForAuthorities(ctx context.Context, concordances Concordances, tid string, idsByAuthority map[string][]string) (map[string][]Identifier, map[string]error) {
+	identifiers := make(map[string][]Identifier)
+	errs := make(map[string]error)
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for authority, ids := range idsByAuthority {
+		wg.Add(1)
+		go func(authority string, ids []string) {
+			defer wg.Done()
+			fetched, err := concordances.GetConcordances(ctx, tid, authority, ids...)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs[authority] = err
+			}
+			for uuid, concorded := range fetched {
+				identifiers[uuid] = appendMissingIdentifiers(identifiers[uuid], concorded...)
+			}
+		}(authority, ids)
+	}
+	wg.Wait()
+
+	return identifiers, errs
+}
+
+func (c *publicConcordancesAPI) fetchConcordances(ctx context.Context, tid, authority string, ids []string) (map[string][]Identifier, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.uri+"/concordances", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +111,7 @@ func (c *publicConcordancesAPI) GetConcordances(tid, authority string, ids ...st
 	req.URL.RawQuery = queryParams.Encode()
 
 	stampRequest(req, tid)
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -91,6 +144,22 @@ func concordancesToIdentifiers(concordances []Concordance) map[string][]Identifi
 	return identifiers
 }
 
+func appendMissingIdentifiers(identifiers []Identifier, toAppend ...Identifier) []Identifier {
+	for _, identifier := range toAppend {
+		found := false
+		for _, existing := range identifiers {
+			if existing == identifier {
+				found = true
+				break
+			}
+		}
+		if !found {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	return identifiers
+}
+
 func (c *publicConcordancesAPI) Check() fthealth.Check {
 	return fthealth.Check{
 		ID:               "public-concordance-api",
@@ -99,7 +168,7 @@ func (c *publicConcordancesAPI) Check() fthealth.Check {
 		PanicGuide:       "https://runbooks.in.ft.com/internal-concordances",
 		Severity:         2,
 		TechnicalSummary: "Public Concordance API is not available",
-		Checker:          c.gtg,
+		Checker:          c.withCircuitState(c.gtg),
 	}
 }
 
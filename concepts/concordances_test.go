@@ -1,11 +1,14 @@
 package concepts
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
@@ -28,7 +31,7 @@ func TestGetConcordancesEmptyResponse(t *testing.T) {
 	defer server.Close()
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
-	identifiers, err := concordances.GetConcordances("tid_TestGetConcordancesEmptyResponse", NoAuthority, requestedUUIDs...)
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesEmptyResponse", NoAuthority, requestedUUIDs...)
 
 	assert.NoError(t, err)
 	assert.Len(t, identifiers, 0)
@@ -45,7 +48,7 @@ func TestGetConcordancesAtLeastOneNonEmptyID(t *testing.T) {
 	defer server.Close()
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
-	identifiers, err := concordances.GetConcordances("tid_TestGetConcordancesAtLeastOneNonEmptyID", NoAuthority, requestedUUIDs...)
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesAtLeastOneNonEmptyID", NoAuthority, requestedUUIDs...)
 
 	assert.NoError(t, err)
 	assert.Len(t, identifiers, 0)
@@ -65,7 +68,7 @@ func TestGetConcordances(t *testing.T) {
 	defer server.Close()
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
-	identifiers, err := concordances.GetConcordances("tid_TestGetEmptyConcordances", NoAuthority, requestedUUIDs...)
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestGetEmptyConcordances", NoAuthority, requestedUUIDs...)
 
 	assert.NoError(t, err)
 	assert.Len(t, identifiers, 1)
@@ -90,7 +93,7 @@ func TestGetConcordancesByAuthority(t *testing.T) {
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
 	uppAuthority := "http://api.ft.com/system/UPP"
-	identifiers, err := concordances.GetConcordances("tid_TestGetConcordancesByAuthority", uppAuthority, requestedUUIDs...)
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesByAuthority", uppAuthority, requestedUUIDs...)
 
 	assert.NoError(t, err)
 	assert.Len(t, identifiers, 1)
@@ -105,28 +108,28 @@ func TestGetConcordancesByAuthority(t *testing.T) {
 
 func TestGetConcordancesFailsWhenNoIDsSupplied(t *testing.T) {
 	concordances := NewConcordances(&http.Client{}, "")
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesFailsWhenNoIDsSupplied", NoAuthority)
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesFailsWhenNoIDsSupplied", NoAuthority)
 
 	assert.EqualError(t, err, ErrNoConceptsToSearch.Error())
 }
 
 func TestGetConcordancesFailsWhenEmptyIDsSupplied(t *testing.T) {
 	concordances := NewConcordances(&http.Client{}, "")
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesFailsWhenEmptyIDsSupplied", NoAuthority, "", "")
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesFailsWhenEmptyIDsSupplied", NoAuthority, "", "")
 
 	assert.EqualError(t, err, ErrConceptIDsAreEmpty.Error())
 }
 
 func TestGetConcordancesFailsInvalidURL(t *testing.T) {
 	concordances := NewConcordances(&http.Client{}, ":#") // this triggers a invalid url during the http.NewRequest() line
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesFailsInvalidURL", NoAuthority, uuid.New().String())
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesFailsInvalidURL", NoAuthority, uuid.New().String())
 
 	assert.Error(t, err)
 }
 
 func TestGetConcordancesRequestFails(t *testing.T) {
 	concordances := NewConcordances(&http.Client{}, "#:") // this triggers a protocol error in the client.Do()
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesRequestFails", NoAuthority, uuid.New().String())
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesRequestFails", NoAuthority, uuid.New().String())
 
 	assert.Error(t, err)
 }
@@ -141,7 +144,7 @@ func TestGetConcordancesResponseJSONInvalid(t *testing.T) {
 	defer server.Close()
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesResponseJSONInvalid", NoAuthority, requestedUUIDs...)
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesResponseJSONInvalid", NoAuthority, requestedUUIDs...)
 
 	assert.Error(t, err)
 	serverMock.AssertExpectations(t) // failure here means the concordances API has not been called
@@ -157,7 +160,7 @@ func TestGetConcordancesFailedResponse(t *testing.T) {
 	defer server.Close()
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesFailedResponse", NoAuthority, requestedUUIDs...)
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesFailedResponse", NoAuthority, requestedUUIDs...)
 
 	assert.EqualError(t, err, "503 Service Unavailable: uh oh")
 	serverMock.AssertExpectations(t) // failure here means the concordances API has not been called
@@ -173,7 +176,7 @@ func TestGetConcordancesFailedResponseMessageDecodingAlsoFailed(t *testing.T) {
 	defer server.Close()
 
 	concordances := NewConcordances(&http.Client{}, server.URL)
-	_, err := concordances.GetConcordances("tid_TestGetConcordancesFailedResponse", NoAuthority, requestedUUIDs...)
+	_, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesFailedResponse", NoAuthority, requestedUUIDs...)
 
 	assert.EqualError(t, err, "400 Bad Request: Failed to decode message from response")
 	serverMock.AssertExpectations(t) // failure here means the concordances API has not been called
@@ -282,3 +285,80 @@ func newPublicConcordanceAPIGTGMock(t *testing.T, status int) *httptest.Server {
 	})
 	return httptest.NewServer(r)
 }
+
+func TestGetConcordancesInChunks(t *testing.T) {
+	var mutex sync.Mutex
+	var requested [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query()[identifierValueQueryParam]
+
+		mutex.Lock()
+		requested = append(requested, ids)
+		mutex.Unlock()
+
+		resp := publicConcordancesResponse{}
+		for _, id := range ids {
+			resp.Concordances = append(resp.Concordances, Concordance{
+				Concept:    Concept{ID: apiIDPrefix + "uuid-1"},
+				Identifier: Identifier{Authority: tmeAuthority, IdentifierValue: id},
+			})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	concordances := NewConcordances(&http.Client{}, server.URL, WithChunking(2, 2))
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesInChunks", tmeAuthority, "tme-1", "tme-2", "tme-3")
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, [][]string{{"tme-1", "tme-2"}, {"tme-3"}}, requested)
+	assert.Len(t, identifiers, 1)
+	assert.ElementsMatch(t, []Identifier{
+		{Authority: tmeAuthority, IdentifierValue: "tme-1"},
+		{Authority: tmeAuthority, IdentifierValue: "tme-2"},
+		{Authority: tmeAuthority, IdentifierValue: "tme-3"},
+	}, identifiers["uuid-1"])
+}
+
+func TestGetConcordancesInChunksAllFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"message":"uh oh"}`))
+	}))
+	defer server.Close()
+
+	concordances := NewConcordances(&http.Client{}, server.URL, WithChunking(1, 2))
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestGetConcordancesInChunksAllFail", tmeAuthority, "tme-1", "tme-2")
+
+	assert.EqualError(t, err, "503 Service Unavailable: uh oh")
+	assert.Nil(t, identifiers)
+}
+
+func TestGetConcordancesForAuthorities(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestGetConcordancesForAuthorities", tmeAuthority, []string{"tme-1"}).
+		Return(map[string][]Identifier{"uuid-1": {{Authority: tmeAuthority, IdentifierValue: "tme-1"}}}, nil)
+	concordances.On("GetConcordances", "tid_TestGetConcordancesForAuthorities", "http://api.ft.com/system/SMARTLOGIC", []string{"smartlogic-1", "smartlogic-2"}).
+		Return(map[string][]Identifier{
+			"uuid-1": {{Authority: "http://api.ft.com/system/SMARTLOGIC", IdentifierValue: "smartlogic-1"}},
+			"uuid-2": {{Authority: "http://api.ft.com/system/SMARTLOGIC", IdentifierValue: "smartlogic-2"}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestGetConcordancesForAuthorities", "http://api.ft.com/system/FACTSET", []string{"factset-1"}).
+		Return(map[string][]Identifier(nil), errors.New("computer says no"))
+
+	identifiers, errs := GetConcordancesForAuthorities(context.Background(), concordances, "tid_TestGetConcordancesForAuthorities", map[string][]string{
+		tmeAuthority:                          {"tme-1"},
+		"http://api.ft.com/system/SMARTLOGIC": {"smartlogic-2", "smartlogic-1"},
+		"http://api.ft.com/system/FACTSET":    {"factset-1"},
+	})
+
+	assert.Len(t, identifiers, 2)
+	assert.ElementsMatch(t, []Identifier{
+		{Authority: tmeAuthority, IdentifierValue: "tme-1"},
+		{Authority: "http://api.ft.com/system/SMARTLOGIC", IdentifierValue: "smartlogic-1"},
+	}, identifiers["uuid-1"])
+	assert.Equal(t, []Identifier{{Authority: "http://api.ft.com/system/SMARTLOGIC", IdentifierValue: "smartlogic-2"}}, identifiers["uuid-2"])
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs["http://api.ft.com/system/FACTSET"], "computer says no")
+	concordances.AssertExpectations(t)
+}
@@ -0,0 +1,41 @@
+package concepts
+
+import (
+	"context"
+	"sort"
+
+	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSearch struct {
+	mock.Mock
+}
+
+func (m *mockSearch) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error) {
+	uuids = append([]string(nil), uuids...)
+	sort.Strings(uuids)
+	args := m.Called(tid, uuids)
+	return args.Get(0).(map[string]Concept), args.Error(1)
+}
+
+func (m *mockSearch) Check() fthealth.Check {
+	args := m.Called()
+	return args.Get(0).(fthealth.Check)
+}
+
+type mockConcordances struct {
+	mock.Mock
+}
+
+func (m *mockConcordances) GetConcordances(ctx context.Context, tid, authority string, ids ...string) (map[string][]Identifier, error) {
+	ids = append([]string(nil), ids...)
+	sort.Strings(ids)
+	args := m.Called(tid, authority, ids)
+	return args.Get(0).(map[string][]Identifier), args.Error(1)
+}
+
+func (m *mockConcordances) Check() fthealth.Check {
+	args := m.Called()
+	return args.Get(0).(fthealth.Check)
+}
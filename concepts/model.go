@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path"
 	"strings"
 )
 
@@ -14,6 +15,90 @@ type Concept struct {
 	PrefLabel    string `json:"prefLabel,omitempty"`
 	IsFTAuthor   *bool  `json:"isFTAuthor,omitempty"`
 	IsDeprecated bool   `json:"isDeprecated,omitempty"`
+	// The optional fields below are only returned to clients which select them
+	Aliases        []string `json:"aliases,omitempty"`
+	ScopeNote      string   `json:"scopeNote,omitempty"`
+	DescriptionXML string   `json:"descriptionXML,omitempty"`
+	DirectType     string   `json:"directType,omitempty"`
+	Types          []string `json:"types,omitempty"`
+	ImageURL       string   `json:"imageUrl,omitempty"`
+	EmailAddress   string   `json:"emailAddress,omitempty"`
+	// SupersededBy is the id of the concept which replaced this deprecated concept, if known
+	SupersededBy string `json:"supersededBy,omitempty"`
+	// Identifiers are every identifier concorded to the concept, only populated on request
+	Identifiers []Identifier `json:"identifiers,omitempty"`
+	// Stale is set when the concept was served from cache past its ttl, because concept search could not be reached
+	Stale bool `json:"-"`
+}
+
+// UUID returns the canonical uuid of the concept, taken from its id
+func (c Concept) UUID() string {
+	return NormaliseID(c.ID)
+}
+
+// HasType reports whether the concept is of the given type, given either as its full ontology URI or its case insensitive short name,
+// such as http://www.ft.com/ontology/person/Person or person. The type may be any of the concept's types, not only its most specific one.
+func (c Concept) HasType(conceptType string) bool {
+	for _, t := range append([]string{c.Type, c.DirectType}, c.Types...) {
+		if t == "" {
+			continue
+		}
+		if t == conceptType || (!strings.Contains(conceptType, "/") && strings.EqualFold(path.Base(t), conceptType)) {
+			return true
+		}
+	}
+	return false
+}
+
+var optionalFields = []string{"aliases", "scopeNote", "descriptionXML", "directType", "types", "imageUrl", "emailAddress", "supersededBy"}
+
+// OptionalFields returns the names of the concept fields which are only returned on request
+func OptionalFields() []string {
+	return append([]string(nil), optionalFields...)
+}
+
+// IsOptionalField reports whether the field is one of the optional fields of a concept
+func IsOptionalField(field string) bool {
+	for _, f := range optionalFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// WithFields returns the concept with only the given optional fields set
+func (c Concept) WithFields(fields ...string) Concept {
+	selected := make(map[string]bool)
+	for _, field := range fields {
+		selected[field] = true
+	}
+
+	if !selected["aliases"] {
+		c.Aliases = nil
+	}
+	if !selected["scopeNote"] {
+		c.ScopeNote = ""
+	}
+	if !selected["descriptionXML"] {
+		c.DescriptionXML = ""
+	}
+	if !selected["directType"] {
+		c.DirectType = ""
+	}
+	if !selected["types"] {
+		c.Types = nil
+	}
+	if !selected["imageUrl"] {
+		c.ImageURL = ""
+	}
+	if !selected["emailAddress"] {
+		c.EmailAddress = ""
+	}
+	if !selected["supersededBy"] {
+		c.SupersededBy = ""
+	}
+	return c
 }
 
 type Identifier struct {
@@ -59,3 +144,11 @@ func stripThingPrefix(conceptID string) (string, bool) {
 
 	return "", false
 }
+
+// NormaliseID returns the uuid of a thing URI, in either its www.ft.com or api.ft.com form, and any other id unchanged
+func NormaliseID(id string) string {
+	if uuid, ok := stripThingPrefix(id); ok {
+		return uuid
+	}
+	return id
+}
@@ -0,0 +1,72 @@
+package concepts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConceptUUID(t *testing.T) {
+	assert.Equal(t, "a-uuid", Concept{ID: "http://www.ft.com/thing/a-uuid"}.UUID())
+	assert.Equal(t, "a-uuid", Concept{ID: "http://api.ft.com/things/a-uuid"}.UUID())
+	assert.Equal(t, "a-uuid", Concept{ID: "a-uuid"}.UUID())
+}
+
+func TestNormaliseID(t *testing.T) {
+	assert.Equal(t, "a-uuid", NormaliseID("http://www.ft.com/thing/a-uuid"))
+	assert.Equal(t, "a-uuid", NormaliseID("http://api.ft.com/things/a-uuid"))
+	assert.Equal(t, "a-uuid", NormaliseID("a-uuid"))
+	assert.Equal(t, "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=", NormaliseID("TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="))
+}
+
+func TestConceptWithFields(t *testing.T) {
+	concept := Concept{
+		ID:             "http://www.ft.com/thing/a-uuid",
+		PrefLabel:      "Donald Trump",
+		Aliases:        []string{"Trump"},
+		ScopeNote:      "A note",
+		DescriptionXML: "<p>A description</p>",
+		DirectType:     "http://www.ft.com/ontology/person/Person",
+		Types:          []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/person/Person"},
+		ImageURL:       "http://example.com/image.jpg",
+		EmailAddress:   "someone@example.com",
+	}
+
+	assert.Equal(t, Concept{ID: concept.ID, PrefLabel: concept.PrefLabel}, concept.WithFields())
+
+	selected := concept.WithFields("aliases", "directType")
+	assert.Equal(t, Concept{ID: concept.ID, PrefLabel: concept.PrefLabel, Aliases: concept.Aliases, DirectType: concept.DirectType}, selected)
+
+	assert.Equal(t, concept, concept.WithFields(OptionalFields()...))
+}
+
+func TestIsOptionalField(t *testing.T) {
+	for _, field := range OptionalFields() {
+		assert.True(t, IsOptionalField(field), field)
+	}
+	assert.False(t, IsOptionalField("prefLabel"))
+	assert.False(t, IsOptionalField("Aliases"))
+}
+
+func TestConceptHasType(t *testing.T) {
+	person := Concept{ID: "http://www.ft.com/thing/a-uuid", Type: "http://www.ft.com/ontology/person/Person"}
+	assert.True(t, person.HasType("http://www.ft.com/ontology/person/Person"))
+	assert.True(t, person.HasType("Person"))
+	assert.True(t, person.HasType("person"))
+	assert.False(t, person.HasType("http://www.ft.com/ontology/organisation/Organisation"))
+	assert.False(t, person.HasType("Organisation"))
+	assert.False(t, person.HasType("http://www.ft.com/ontology/Person"))
+
+	company := Concept{
+		ID:         "http://www.ft.com/thing/another-uuid",
+		Type:       "http://www.ft.com/ontology/company/PublicCompany",
+		DirectType: "http://www.ft.com/ontology/company/PublicCompany",
+		Types:      []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/organisation/Organisation", "http://www.ft.com/ontology/company/PublicCompany"},
+	}
+	assert.True(t, company.HasType("PublicCompany"))
+	assert.True(t, company.HasType("organisation"))
+	assert.True(t, company.HasType("http://www.ft.com/ontology/organisation/Organisation"))
+	assert.False(t, company.HasType("Person"))
+
+	assert.False(t, Concept{ID: "http://www.ft.com/thing/untyped-uuid"}.HasType("Person"))
+}
@@ -0,0 +1,79 @@
+package concepts
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// RetryPolicy describes how failed requests to an upstream API are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a request, including the first
+	MaxAttempts int
+	// InitialBackoff is the upper bound of the randomised wait before the first retry, which doubles for every further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the upper bound of the wait between attempts
+	MaxBackoff time.Duration
+	// RetryableStatuses are the response statuses which are retried, network errors are always retried
+	RetryableStatuses []int
+}
+
+// WithRetries retries failed requests to the upstream API according to the policy, as long as the request deadline allows
+func WithRetries(policy RetryPolicy) Option {
+	return func(a *httpAPI) {
+		a.retry = policy
+	}
+}
+
+// doWithRetries sends the request, retrying it according to the retry policy of the API
+func (a *httpAPI) doWithRetries(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := a.client.Do(req)
+		if attempt >= a.retry.MaxAttempts || !a.retry.shouldRetry(resp, err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		wait := a.retry.backoff(attempt)
+		if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+
+		metrics.GetOrRegisterCounter(a.name+".retries", metrics.DefaultRegistry).Inc(1)
+	}
+}
+
+func (p RetryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	for _, status := range p.RetryableStatuses {
+		if resp.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns a random wait before the given retry, up to an exponentially increasing bound
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	bound := p.InitialBackoff << uint(attempt-1)
+	if bound <= 0 || (p.MaxBackoff > 0 && bound > p.MaxBackoff) {
+		bound = p.MaxBackoff
+	}
+	if bound <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(bound)))
+}
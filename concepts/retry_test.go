@@ -0,0 +1,125 @@
+package concepts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:       3,
+	InitialBackoff:    time.Millisecond,
+	MaxBackoff:        5 * time.Millisecond,
+	RetryableStatuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+}
+
+func newFlakyServer(statuses ...int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+		if int(call) <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+			w.Write([]byte(`{"message":"flaky"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	return server, &calls
+}
+
+func TestRetriesRetryableStatuses(t *testing.T) {
+	server, calls := newFlakyServer(http.StatusBadGateway, http.StatusGatewayTimeout)
+	defer server.Close()
+
+	retries := metrics.GetOrRegisterCounter("public-concordances-api.retries", metrics.DefaultRegistry)
+	before := retries.Count()
+
+	concordances := NewConcordances(&http.Client{}, server.URL, WithRetries(testRetryPolicy))
+	identifiers, err := concordances.GetConcordances(context.Background(), "tid_TestRetriesRetryableStatuses", NoAuthority, "uuid-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, identifiers, 0)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	assert.Equal(t, int64(2), retries.Count()-before)
+}
+
+func TestRetriesGiveUpAfterMaxAttempts(t *testing.T) {
+	server, calls := newFlakyServer(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	search := NewSearch(&http.Client{}, server.URL, WithRetries(testRetryPolicy))
+	_, err := search.ByIDs(context.Background(), "tid_TestRetriesGiveUpAfterMaxAttempts", "uuid-1")
+
+	assert.EqualError(t, err, "503 Service Unavailable: flaky")
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestRetriesDoNotRetryOtherStatuses(t *testing.T) {
+	server, calls := newFlakyServer(http.StatusBadRequest)
+	defer server.Close()
+
+	search := NewSearch(&http.Client{}, server.URL, WithRetries(testRetryPolicy))
+	_, err := search.ByIDs(context.Background(), "tid_TestRetriesDoNotRetryOtherStatuses", "uuid-1")
+
+	assert.EqualError(t, err, "400 Bad Request: flaky")
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	var attempts int32
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&attempts, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	search := NewSearch(client, server.URL, WithRetries(testRetryPolicy))
+	_, err := search.ByIDs(context.Background(), "tid_TestRetriesNetworkErrors", "uuid-1")
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRetriesRespectRequestDeadline(t *testing.T) {
+	server, calls := newFlakyServer(http.StatusBadGateway, http.StatusBadGateway)
+	defer server.Close()
+
+	policy := testRetryPolicy
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	search := NewSearch(&http.Client{}, server.URL, WithRetries(policy))
+	_, err := search.ByIDs(ctx, "tid_TestRetriesRespectRequestDeadline", "uuid-1")
+
+	require.Error(t, err)
+	assert.True(t, atomic.LoadInt32(calls) < 3)
+}
+
+func TestRetryBackoffIsBounded(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond}
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, policy.backoff(1) < 10*time.Millisecond)
+		assert.True(t, policy.backoff(2) < 20*time.Millisecond)
+		assert.True(t, policy.backoff(5) < 25*time.Millisecond)
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -1,10 +1,12 @@
 package concepts
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 )
@@ -19,29 +21,51 @@ var (
 )
 
 type Search interface {
-	ByIDs(tid string, uuids ...string) (map[string]Concept, error)
+	ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error)
 	Check() fthealth.Check
 }
 
 type conceptSearchAPI struct {
-	client *http.Client
-	uri    string
+	httpAPI
 }
 
 type conceptSearchResponse struct {
 	Concepts []Concept `json:"concepts"`
 }
 
-func NewSearch(client *http.Client, uri string) Search {
-	return &conceptSearchAPI{client: client, uri: uri}
+func NewSearch(client *http.Client, uri string, opts ...Option) Search {
+	return &conceptSearchAPI{httpAPI: newHTTPAPI("concept-search-api", client, uri, opts)}
 }
 
-func (c *conceptSearchAPI) ByIDs(tid string, uuids ...string) (map[string]Concept, error) {
+func (c *conceptSearchAPI) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]Concept, error) {
 	if err := validateIDs(uuids); err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("GET", c.uri+"/concepts", nil)
+	concepts := make(map[string]Concept)
+	var mutex sync.Mutex
+	err := c.fetchInChunks(uuids, func(chunk []string) error {
+		fetched, err := c.fetchConcepts(ctx, tid, chunk)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		for uuid, concept := range fetched {
+			concepts[uuid] = concept
+		}
+		return nil
+	})
+
+	if _, partial := err.(*PartialError); err != nil && !partial {
+		return nil, err
+	}
+	return concepts, err
+}
+
+func (c *conceptSearchAPI) fetchConcepts(ctx context.Context, tid string, uuids []string) (map[string]Concept, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.uri+"/concepts", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +78,7 @@ func (c *conceptSearchAPI) ByIDs(tid string, uuids ...string) (map[string]Concep
 	req.URL.RawQuery = queryParams.Encode()
 
 	stampRequest(req, tid)
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -111,7 +135,7 @@ func (c *conceptSearchAPI) Check() fthealth.Check {
 		PanicGuide:       "https://runbooks.in.ft.com/internal-concordances",
 		Severity:         2,
 		TechnicalSummary: "Concept Search API is not available",
-		Checker:          c.gtg,
+		Checker:          c.withCircuitState(c.gtg),
 	}
 }
 
@@ -1,12 +1,16 @@
 package concepts
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 	uuid "github.com/google/uuid"
@@ -26,7 +30,7 @@ func TestSearchByIDsNoResults(t *testing.T) {
 	defer server.Close()
 
 	search := NewSearch(&http.Client{}, server.URL)
-	concepts, err := search.ByIDs("tid_TestSearchByIDsNoResults", requestedUUIDs...)
+	concepts, err := search.ByIDs(context.Background(), "tid_TestSearchByIDsNoResults", requestedUUIDs...)
 
 	assert.NoError(t, err)
 	assert.Len(t, concepts, 0)
@@ -46,37 +50,43 @@ func TestSearchByIDs(t *testing.T) {
 	defer server.Close()
 
 	search := NewSearch(&http.Client{}, server.URL)
-	concepts, err := search.ByIDs("tid_TestSearchByIDs", requestedUUIDs...)
+	concepts, err := search.ByIDs(context.Background(), "tid_TestSearchByIDs", requestedUUIDs...)
 
 	assert.NoError(t, err)
 	assert.Len(t, concepts, 1)
+
+	concept := concepts["6b43a14b-a5e0-3b63-a428-aa55def05fcb"]
+	assert.Equal(t, []string{"FTCR"}, concept.Aliases)
+	assert.Equal(t, "Research on China and emerging Asia", concept.ScopeNote)
+	assert.Equal(t, "http://www.ft.com/ontology/Section", concept.DirectType)
+	assert.Len(t, concept.Types, 4)
 	serverMock.AssertExpectations(t) // failure here means the search API has not been called
 }
 
 func TestSearchNoIDsProvided(t *testing.T) {
 	search := NewSearch(&http.Client{}, "")
-	_, err := search.ByIDs("tid_TestSearchNoIDsProvided")
+	_, err := search.ByIDs(context.Background(), "tid_TestSearchNoIDsProvided")
 
 	assert.EqualError(t, err, ErrNoConceptsToSearch.Error())
 }
 
 func TestSearchAllIDsProvidedEmpty(t *testing.T) {
 	search := NewSearch(&http.Client{}, "")
-	_, err := search.ByIDs("tid_TestSearchNoIDsProvided", "", "", "", "")
+	_, err := search.ByIDs(context.Background(), "tid_TestSearchNoIDsProvided", "", "", "", "")
 
 	assert.EqualError(t, err, ErrConceptIDsAreEmpty.Error())
 }
 
 func TestSearchRequestURLInvalid(t *testing.T) {
 	search := NewSearch(&http.Client{}, ":#")
-	_, err := search.ByIDs("tid_TestSearchRequestURLInvalid", uuid.New().String())
+	_, err := search.ByIDs(context.Background(), "tid_TestSearchRequestURLInvalid", uuid.New().String())
 
 	assert.Error(t, err)
 }
 
 func TestSearchRequestFails(t *testing.T) {
 	search := NewSearch(&http.Client{}, "#:")
-	_, err := search.ByIDs("tid_TestSearchRequestFails", uuid.New().String())
+	_, err := search.ByIDs(context.Background(), "tid_TestSearchRequestFails", uuid.New().String())
 
 	assert.Error(t, err)
 }
@@ -91,7 +101,7 @@ func TestSearchResponseFailed(t *testing.T) {
 	defer server.Close()
 
 	search := NewSearch(&http.Client{}, server.URL)
-	_, err := search.ByIDs("tid_TestSearchResponseFailed", requestedUUIDs...)
+	_, err := search.ByIDs(context.Background(), "tid_TestSearchResponseFailed", requestedUUIDs...)
 
 	assert.EqualError(t, err, "403 Forbidden: forbidden!!!!!")
 	serverMock.AssertExpectations(t) // failure here means the search API has not been called
@@ -107,7 +117,7 @@ func TestSearchResponseInvalidJSON(t *testing.T) {
 	defer server.Close()
 
 	search := NewSearch(&http.Client{}, server.URL)
-	_, err := search.ByIDs("tid_TestSearchResponseInvalidJSON", requestedUUIDs...)
+	_, err := search.ByIDs(context.Background(), "tid_TestSearchResponseInvalidJSON", requestedUUIDs...)
 
 	assert.Error(t, err)
 	serverMock.AssertExpectations(t) // failure here means the search API has not been called
@@ -209,3 +219,55 @@ func newConceptSearchAPIGTGMock(t *testing.T, status int) *httptest.Server {
 	})
 	return httptest.NewServer(r)
 }
+
+func TestSearchByIDsInChunks(t *testing.T) {
+	var mutex sync.Mutex
+	var requested [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := r.URL.Query()[conceptSearchQueryParam]
+
+		mutex.Lock()
+		requested = append(requested, ids)
+		mutex.Unlock()
+
+		if ids[0] == "uuid-3" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		resp := conceptSearchResponse{}
+		for _, id := range ids {
+			resp.Concepts = append(resp.Concepts, Concept{ID: ftIDPrefix + id})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	search := NewSearch(&http.Client{}, server.URL, WithChunking(2, 2))
+	concepts, err := search.ByIDs(context.Background(), "tid_TestSearchByIDsInChunks", "uuid-1", "uuid-2", "uuid-3", "uuid-4", "uuid-5")
+
+	assert.ElementsMatch(t, [][]string{{"uuid-1", "uuid-2"}, {"uuid-3", "uuid-4"}, {"uuid-5"}}, requested)
+	assert.Len(t, concepts, 3)
+	assert.Contains(t, concepts, "uuid-5")
+
+	var partialErr *PartialError
+	require.True(t, errors.As(err, &partialErr))
+	assert.Len(t, partialErr.Failed, 2)
+	assert.Contains(t, partialErr.Failed, "uuid-3")
+	assert.Contains(t, partialErr.Failed, "uuid-4")
+}
+
+func TestSearchByIDsCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	search := NewSearch(&http.Client{}, server.URL)
+	_, err := search.ByIDs(ctx, "tid_TestSearchByIDsCancelled", uuid.New().String())
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,41 @@
+package concepts
+
+import "context"
+
+// GetSuccessors looks up the live successor of each of the deprecated concepts, keyed by the uuid of the deprecated concept.
+// The successor is the concept which superseded the deprecated concept, as only SupersededBy is followed: the deprecated
+// concepts are keyed by the canonical uuids they were concorded to, so concording them again only leads back to themselves.
+// Deprecated concepts without a successor are left out, as are successors which are themselves deprecated.
+func GetSuccessors(ctx context.Context, search Search, tid string, deprecated map[string]Concept) (map[string]Concept, error) {
+	successorUUIDs := make(map[string]string)
+	for uuid, concept := range deprecated {
+		if concept.SupersededBy != "" {
+			successorUUIDs[uuid] = NormaliseID(concept.SupersededBy)
+		}
+	}
+
+	successors := make(map[string]Concept)
+	if len(successorUUIDs) == 0 {
+		return successors, nil
+	}
+
+	var uuids []string
+	seen := make(map[string]bool)
+	for _, uuid := range successorUUIDs {
+		if !seen[uuid] {
+			seen[uuid] = true
+			uuids = append(uuids, uuid)
+		}
+	}
+
+	found, err := search.ByIDs(ctx, tid, uuids...)
+	if err != nil {
+		return nil, err
+	}
+	for deprecatedUUID, successorUUID := range successorUUIDs {
+		if successor, ok := found[successorUUID]; ok && !successor.IsDeprecated {
+			successors[deprecatedUUID] = successor
+		}
+	}
+	return successors, nil
+}
@@ -0,0 +1,55 @@
+package concepts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSuccessors(t *testing.T) {
+	search := new(mockSearch)
+
+	deprecated := map[string]Concept{
+		"uuid-superseded": {ID: "http://www.ft.com/thing/uuid-superseded", IsDeprecated: true, SupersededBy: "http://www.ft.com/thing/uuid-successor"},
+		"uuid-abandoned":  {ID: "http://www.ft.com/thing/uuid-abandoned", IsDeprecated: true},
+		"uuid-chained":    {ID: "http://www.ft.com/thing/uuid-chained", IsDeprecated: true, SupersededBy: "uuid-also-deprecated"},
+	}
+
+	search.On("ByIDs", "tid_TestGetSuccessors", []string{"uuid-also-deprecated", "uuid-successor"}).
+		Return(map[string]Concept{
+			"uuid-successor":       {ID: "http://www.ft.com/thing/uuid-successor", PrefLabel: "Successor"},
+			"uuid-also-deprecated": {ID: "http://www.ft.com/thing/uuid-also-deprecated", IsDeprecated: true},
+		}, nil)
+
+	successors, err := GetSuccessors(context.Background(), search, "tid_TestGetSuccessors", deprecated)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Concept{
+		"uuid-superseded": {ID: "http://www.ft.com/thing/uuid-successor", PrefLabel: "Successor"},
+	}, successors)
+	search.AssertExpectations(t)
+}
+
+func TestGetSuccessorsWithoutSupersededBy(t *testing.T) {
+	successors, err := GetSuccessors(context.Background(), nil, "tid_TestGetSuccessorsWithoutSupersededBy", map[string]Concept{
+		"uuid-abandoned": {ID: "http://www.ft.com/thing/uuid-abandoned", IsDeprecated: true},
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, successors)
+}
+
+func TestGetSuccessorsFails(t *testing.T) {
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestGetSuccessorsFails", []string{"uuid-successor"}).
+		Return(map[string]Concept(nil), errors.New("computer says no"))
+
+	_, err := GetSuccessors(context.Background(), search, "tid_TestGetSuccessorsFails", map[string]Concept{
+		"uuid-superseded": {ID: "http://www.ft.com/thing/uuid-superseded", IsDeprecated: true, SupersededBy: "uuid-successor"},
+	})
+
+	assert.EqualError(t, err, "computer says no")
+	search.AssertExpectations(t)
+}
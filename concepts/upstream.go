@@ -0,0 +1,158 @@
+package concepts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Option configures the http clients used to call the upstream APIs
+type Option func(*httpAPI)
+
+// WithChunking splits the ids sent to the upstream API into requests of at most chunkSize ids,
+// of which at most maxConcurrentRequests are in flight at once.
+func WithChunking(chunkSize int, maxConcurrentRequests int) Option {
+	return func(a *httpAPI) {
+		a.chunkSize = chunkSize
+		a.maxConcurrentRequests = maxConcurrentRequests
+	}
+}
+
+// PartialError is returned alongside the merged results of the chunks which succeeded, when only some chunks of a request failed
+type PartialError struct {
+	// Failed maps every id which could not be fetched to the error of its chunk
+	Failed map[string]error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("failed to fetch %v of the requested ids", len(e.Failed))
+}
+
+// Unwrap returns the errors of the failed ids, so errors.Is and errors.As see through the partial failure
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// FailureFor returns the error for the id, when err is either a complete failure or a partial failure including the id
+func FailureFor(id string, err error) error {
+	var partialErr *PartialError
+	if errors.As(err, &partialErr) {
+		return partialErr.Failed[id]
+	}
+	return err
+}
+
+type httpAPI struct {
+	name                  string
+	client                *http.Client
+	uri                   string
+	chunkSize             int
+	maxConcurrentRequests int
+	retry                 RetryPolicy
+	breaker               *circuitBreaker
+}
+
+func newHTTPAPI(name string, client *http.Client, uri string, opts []Option) httpAPI {
+	a := httpAPI{name: name, client: client, uri: uri}
+	for _, opt := range opts {
+		opt(&a)
+	}
+	return a
+}
+
+// do sends the request with retries, unless the circuit breaker of the API is open
+func (a *httpAPI) do(req *http.Request) (*http.Response, error) {
+	if a.breaker == nil {
+		return a.doWithRetries(req)
+	}
+
+	if allowed, retryAfter := a.breaker.allow(); !allowed {
+		return nil, &CircuitOpenError{Upstream: a.name, RetryAfter: retryAfter}
+	}
+
+	resp, err := a.doWithRetries(req)
+	a.breaker.record(isUpstreamFailure(resp, err))
+	return resp, err
+}
+
+// withCircuitState reports the state of the circuit breaker of the API in the message of the health checker.
+// An open circuit breaker does not fail the checker, as it is part of GTG, and the stale cache and partial responses keep serving meanwhile.
+func (a *httpAPI) withCircuitState(checker func() (string, error)) func() (string, error) {
+	return func() (string, error) {
+		msg, err := checker()
+		if err != nil || a.breaker == nil {
+			return msg, err
+		}
+
+		if state := a.breaker.current(); state != circuitClosed {
+			return fmt.Sprintf("%v, circuit breaker is %v", msg, state), nil
+		}
+		return msg, nil
+	}
+}
+
+// fetchInChunks calls fetch for every chunk of the ids. If every chunk fails the first error is returned,
+// otherwise a PartialError lists the ids of the chunks which failed.
+func (a *httpAPI) fetchInChunks(ids []string, fetch func(chunk []string) error) error {
+	chunks := splitIntoChunks(ids, a.chunkSize)
+	if len(chunks) == 1 {
+		return fetch(chunks[0])
+	}
+
+	maxConcurrentRequests := a.maxConcurrentRequests
+	if maxConcurrentRequests < 1 {
+		maxConcurrentRequests = 1
+	}
+
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, maxConcurrentRequests)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fetch(chunk)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	failed := make(map[string]error)
+	failedChunks := 0
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		failedChunks++
+		for _, id := range chunks[i] {
+			failed[id] = err
+		}
+	}
+
+	switch failedChunks {
+	case 0:
+		return nil
+	case len(chunks):
+		return errs[0]
+	}
+	return &PartialError{Failed: failed}
+}
+
+func splitIntoChunks(ids []string, chunkSize int) [][]string {
+	if chunkSize < 1 || len(ids) <= chunkSize {
+		return [][]string{ids}
+	}
+
+	var chunks [][]string
+	for len(ids) > chunkSize {
+		chunks = append(chunks, ids[:chunkSize])
+		ids = ids[chunkSize:]
+	}
+	return append(chunks, ids)
+}
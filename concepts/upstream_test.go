@@ -0,0 +1,102 @@
+package concepts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitIntoChunks(t *testing.T) {
+	ids := []string{"1", "2", "3", "4", "5"}
+
+	assert.Equal(t, [][]string{ids}, splitIntoChunks(ids, 0))
+	assert.Equal(t, [][]string{ids}, splitIntoChunks(ids, 5))
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, splitIntoChunks(ids, 2))
+}
+
+func TestFetchInChunks(t *testing.T) {
+	api := newHTTPAPI("test-api", &http.Client{}, "", []Option{WithChunking(2, 2)})
+
+	var mutex sync.Mutex
+	var fetched [][]string
+	err := api.fetchInChunks([]string{"1", "2", "3", "4", "5"}, func(chunk []string) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fetched = append(fetched, chunk)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, fetched)
+}
+
+func TestFetchInChunksBoundsConcurrentRequests(t *testing.T) {
+	api := newHTTPAPI("test-api", &http.Client{}, "", []Option{WithChunking(1, 2)})
+
+	var inFlight, maxInFlight int32
+	err := api.fetchInChunks([]string{"1", "2", "3", "4", "5", "6"}, func(chunk []string) error {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), maxInFlight)
+}
+
+func TestFetchInChunksPartialFailure(t *testing.T) {
+	api := newHTTPAPI("test-api", &http.Client{}, "", []Option{WithChunking(2, 2)})
+
+	err := api.fetchInChunks([]string{"1", "2", "3"}, func(chunk []string) error {
+		if chunk[0] == "1" {
+			return errComputerSaysNo
+		}
+		return nil
+	})
+
+	assert.Equal(t, &PartialError{Failed: map[string]error{"1": errComputerSaysNo, "2": errComputerSaysNo}}, err)
+	assert.EqualError(t, err, "failed to fetch 2 of the requested ids")
+}
+
+func TestFetchInChunksCompleteFailure(t *testing.T) {
+	api := newHTTPAPI("test-api", &http.Client{}, "", []Option{WithChunking(2, 2)})
+
+	err := api.fetchInChunks([]string{"1", "2", "3"}, func(chunk []string) error {
+		return errComputerSaysNo
+	})
+
+	assert.Equal(t, errComputerSaysNo, err)
+}
+
+func TestFailureFor(t *testing.T) {
+	assert.Equal(t, errComputerSaysNo, FailureFor("uuid-1", errComputerSaysNo))
+	assert.Nil(t, FailureFor("uuid-1", nil))
+
+	partialErr := &PartialError{Failed: map[string]error{"uuid-1": errComputerSaysNo}}
+	assert.Equal(t, errComputerSaysNo, FailureFor("uuid-1", partialErr))
+	assert.Nil(t, FailureFor("uuid-2", partialErr))
+	assert.Equal(t, errComputerSaysNo, FailureFor("uuid-1", fmt.Errorf("wrapped: %w", partialErr)))
+}
+
+func TestPartialErrorUnwrap(t *testing.T) {
+	circuitErr := &CircuitOpenError{Upstream: "concept-search-api", RetryAfter: time.Second}
+	partialErr := &PartialError{Failed: map[string]error{"uuid-1": errComputerSaysNo, "uuid-2": circuitErr}}
+
+	var found *CircuitOpenError
+	assert.True(t, errors.As(partialErr, &found))
+	assert.Equal(t, circuitErr, found)
+	assert.True(t, errors.Is(partialErr, errComputerSaysNo))
+}
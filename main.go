@@ -50,6 +50,132 @@ func main() {
 		EnvVar: "PUBLIC_CONCORDANCES_ENDPOINT",
 	})
 
+	requestBudget := app.String(cli.StringOpt{
+		Name:   "request-budget",
+		Value:  "8s",
+		Desc:   "Overall time allowed for the upstream calls of a request, shared between public concordances and concept search, which also bounds upstream calls shared by concurrent requests, 0 disables it",
+		EnvVar: "REQUEST_BUDGET",
+	})
+
+	upstreamChunkSize := app.Int(cli.IntOpt{
+		Name:   "upstream-chunk-size",
+		Value:  100,
+		Desc:   "Maximum number of ids sent in a single request to concept search or public concordances, 0 disables chunking",
+		EnvVar: "UPSTREAM_CHUNK_SIZE",
+	})
+
+	upstreamMaxConcurrentRequests := app.Int(cli.IntOpt{
+		Name:   "upstream-max-concurrent-requests",
+		Value:  4,
+		Desc:   "Maximum number of chunked requests in flight to concept search or public concordances for a single lookup",
+		EnvVar: "UPSTREAM_MAX_CONCURRENT_REQUESTS",
+	})
+
+	upstreamRetryMaxAttempts := app.Int(cli.IntOpt{
+		Name:   "upstream-retry-max-attempts",
+		Value:  3,
+		Desc:   "Maximum number of attempts made for a request to concept search or public concordances, 1 disables retries",
+		EnvVar: "UPSTREAM_RETRY_MAX_ATTEMPTS",
+	})
+
+	upstreamRetryInitialBackoff := app.String(cli.StringOpt{
+		Name:   "upstream-retry-initial-backoff",
+		Value:  "100ms",
+		Desc:   "Upper bound of the randomised wait before the first retry, doubled for every further retry",
+		EnvVar: "UPSTREAM_RETRY_INITIAL_BACKOFF",
+	})
+
+	upstreamRetryMaxBackoff := app.String(cli.StringOpt{
+		Name:   "upstream-retry-max-backoff",
+		Value:  "1s",
+		Desc:   "Maximum wait between retries",
+		EnvVar: "UPSTREAM_RETRY_MAX_BACKOFF",
+	})
+
+	upstreamRetryStatuses := app.Ints(cli.IntsOpt{
+		Name:   "upstream-retry-statuses",
+		Value:  []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		Desc:   "Response statuses which are retried, network errors are always retried",
+		EnvVar: "UPSTREAM_RETRY_STATUSES",
+	})
+
+	circuitBreakerFailureRate := app.Int(cli.IntOpt{
+		Name:   "circuit-breaker-failure-rate",
+		Value:  50,
+		Desc:   "Percentage of failed requests to concept search or public concordances within a window which opens its circuit breaker, 0 disables the circuit breakers",
+		EnvVar: "CIRCUIT_BREAKER_FAILURE_RATE",
+	})
+
+	circuitBreakerMinRequests := app.Int(cli.IntOpt{
+		Name:   "circuit-breaker-min-requests",
+		Value:  20,
+		Desc:   "Number of requests a window must contain before a circuit breaker can open",
+		EnvVar: "CIRCUIT_BREAKER_MIN_REQUESTS",
+	})
+
+	circuitBreakerWindow := app.String(cli.StringOpt{
+		Name:   "circuit-breaker-window",
+		Value:  "30s",
+		Desc:   "Period over which the failure rate of a circuit breaker is measured",
+		EnvVar: "CIRCUIT_BREAKER_WINDOW",
+	})
+
+	circuitBreakerOpenDuration := app.String(cli.StringOpt{
+		Name:   "circuit-breaker-open-duration",
+		Value:  "10s",
+		Desc:   "Duration for which an open circuit breaker fails requests, before letting a probe request through",
+		EnvVar: "CIRCUIT_BREAKER_OPEN_DURATION",
+	})
+
+	searchCacheTTL := app.String(cli.StringOpt{
+		Name:   "search-cache-ttl",
+		Value:  "5m",
+		Desc:   "Duration for which concepts returned from concept search are cached, 0 disables the cache",
+		EnvVar: "SEARCH_CACHE_TTL",
+	})
+
+	searchCacheStaleTTL := app.String(cli.StringOpt{
+		Name:   "search-cache-stale-ttl",
+		Value:  "1h",
+		Desc:   "Duration past their ttl for which cached concepts are served if concept search fails",
+		EnvVar: "SEARCH_CACHE_STALE_TTL",
+	})
+
+	searchCacheRefreshAhead := app.String(cli.StringOpt{
+		Name:   "search-cache-refresh-ahead",
+		Value:  "30s",
+		Desc:   "Duration before their expiry in which cached concepts are refreshed in the background, 0 disables background refreshes",
+		EnvVar: "SEARCH_CACHE_REFRESH_AHEAD",
+	})
+
+	searchCacheMaxEntries := app.Int(cli.IntOpt{
+		Name:   "search-cache-max-entries",
+		Value:  10000,
+		Desc:   "Maximum number of concepts held in the concept search cache",
+		EnvVar: "SEARCH_CACHE_MAX_ENTRIES",
+	})
+
+	concordancesCacheTTL := app.String(cli.StringOpt{
+		Name:   "concordances-cache-ttl",
+		Value:  "5m",
+		Desc:   "Duration for which concordances returned from public concordances are cached, 0 disables the cache",
+		EnvVar: "CONCORDANCES_CACHE_TTL",
+	})
+
+	concordancesCacheNegativeTTL := app.String(cli.StringOpt{
+		Name:   "concordances-cache-negative-ttl",
+		Value:  "30s",
+		Desc:   "Duration for which ids that do not concord are cached, 0 disables caching them",
+		EnvVar: "CONCORDANCES_CACHE_NEGATIVE_TTL",
+	})
+
+	concordancesCacheMaxEntries := app.Int(cli.IntOpt{
+		Name:   "concordances-cache-max-entries",
+		Value:  10000,
+		Desc:   "Maximum number of identifiers held in the public concordances cache",
+		EnvVar: "CONCORDANCES_CACHE_MAX_ENTRIES",
+	})
+
 	port := app.String(cli.StringOpt{
 		Name:   "port",
 		Value:  "8080",
@@ -80,12 +206,46 @@ func main() {
 
 		client := &http.Client{Timeout: 8 * time.Second}
 
-		search := concepts.NewSearch(client, *conceptSearchEndpoint)
-		concordances := concepts.NewConcordances(client, *publicConcordancesEndpoint)
+		var caches []concepts.Cache
+
+		upstreamOpts := []concepts.Option{
+			concepts.WithChunking(*upstreamChunkSize, *upstreamMaxConcurrentRequests),
+			concepts.WithRetries(concepts.RetryPolicy{
+				MaxAttempts:       *upstreamRetryMaxAttempts,
+				InitialBackoff:    parseDuration("upstream-retry-initial-backoff", *upstreamRetryInitialBackoff),
+				MaxBackoff:        parseDuration("upstream-retry-max-backoff", *upstreamRetryMaxBackoff),
+				RetryableStatuses: *upstreamRetryStatuses,
+			}),
+			concepts.WithCircuitBreaker(concepts.CircuitBreakerPolicy{ // every upstream gets its own circuit breaker
+				FailureRate:  float64(*circuitBreakerFailureRate) / 100,
+				MinRequests:  *circuitBreakerMinRequests,
+				Window:       parseDuration("circuit-breaker-window", *circuitBreakerWindow),
+				OpenDuration: parseDuration("circuit-breaker-open-duration", *circuitBreakerOpenDuration),
+			}),
+		}
+
+		budget := parseDuration("request-budget", *requestBudget)
+
+		// shared upstream requests last while any request waits on them, bounded by a whole budget of their own
+		search := concepts.NewCoalescingSearch(concepts.NewSearch(client, *conceptSearchEndpoint, upstreamOpts...), budget)
+		if ttl := parseDuration("search-cache-ttl", *searchCacheTTL); ttl > 0 {
+			cachedSearch := concepts.NewCachedSearch(search, ttl,
+				parseDuration("search-cache-stale-ttl", *searchCacheStaleTTL),
+				parseDuration("search-cache-refresh-ahead", *searchCacheRefreshAhead),
+				*searchCacheMaxEntries)
+			search = cachedSearch
+			caches = append(caches, cachedSearch)
+		}
+		concordances := concepts.NewCoalescingConcordances(concepts.NewConcordances(client, *publicConcordancesEndpoint, upstreamOpts...), budget)
+		if ttl := parseDuration("concordances-cache-ttl", *concordancesCacheTTL); ttl > 0 {
+			cachedConcordances := concepts.NewCachedConcordances(concordances, ttl, parseDuration("concordances-cache-negative-ttl", *concordancesCacheNegativeTTL), *concordancesCacheMaxEntries)
+			concordances = cachedConcordances
+			caches = append(caches, cachedConcordances)
+		}
 
 		healthService := health.NewHealthService(*appSystemCode, *appName, appDescription, search.Check(), concordances.Check())
 
-		serveEndpoints(*port, apiYml, healthService, search, concordances)
+		serveEndpoints(*port, apiYml, healthService, search, concordances, caches, budget)
 	}
 
 	err := app.Run(os.Args)
@@ -95,7 +255,15 @@ func main() {
 	}
 }
 
-func serveEndpoints(port string, apiYml *string, healthService *health.HealthService, search concepts.Search, concordances concepts.Concordances) {
+func parseDuration(opt string, value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.WithError(err).Fatalf("Invalid duration for option %v", opt)
+	}
+	return d
+}
+
+func serveEndpoints(port string, apiYml *string, healthService *health.HealthService, search concepts.Search, concordances concepts.Concordances, caches []concepts.Cache, budget time.Duration) {
 	r := vestigo.NewRouter()
 
 	var monitoringRouter http.Handler = r
@@ -105,8 +273,14 @@ func serveEndpoints(port string, apiYml *string, healthService *health.HealthSer
 	r.Get("/__health", healthService.HealthCheckHandleFunc())
 	r.Get(status.GTGPath, status.NewGoodToGoHandler(healthService.GTG))
 	r.Get(status.BuildInfoPath, status.BuildInfoHandler)
+	r.Get("/__cache", resources.CacheStats(caches...))
+	r.Delete("/__cache", resources.PurgeCache(caches...))
 
-	r.Get("/internalconcordances", resources.InternalConcordances(concordances, search))
+	r.Get("/authorities", resources.Authorities())
+	r.Get("/internalconcordances", resources.WithRequestBudget(budget, resources.InternalConcordances(concordances, search)))
+	r.Post("/internalconcordances", resources.WithRequestBudget(budget, resources.InternalConcordancesBatch(concordances, search)))
+	r.Get("/internalconcordances/:id", resources.WithRequestBudget(budget, resources.InternalConcordance(concordances, search)))
+	r.Get("/internalconcordances/:id/identifiers", resources.WithRequestBudget(budget, resources.ConceptIdentifiers(concordances, search)))
 
 	http.Handle("/", monitoringRouter)
 
@@ -0,0 +1,19 @@
+package resources
+
+import (
+	"net/http"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+)
+
+type authoritiesResponse struct {
+	Authorities []concepts.Authority `json:"authorities"`
+}
+
+// Authorities lists the supported authorities, and the aliases which may be given in place of their URIs
+func Authorities() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		writeOK(w, authoritiesResponse{Authorities: concepts.Authorities()})
+	}
+}
@@ -0,0 +1,30 @@
+package resources
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorities(t *testing.T) {
+	req := httptest.NewRequest("GET", "/authorities", nil)
+	w := httptest.NewRecorder()
+
+	Authorities()(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"authorities":[`+
+		`{"uri":"http://api.ft.com/system/UPP","alias":"UPP"},`+
+		`{"uri":"http://api.ft.com/system/FT-TME","alias":"TME"},`+
+		`{"uri":"http://api.ft.com/system/SMARTLOGIC","alias":"SMARTLOGIC"},`+
+		`{"uri":"http://api.ft.com/system/FACTSET","alias":"FACTSET"},`+
+		`{"uri":"http://api.ft.com/system/LEI","alias":"LEI"},`+
+		`{"uri":"http://api.ft.com/system/MANAGEDLOCATION","alias":"MANAGEDLOCATION"},`+
+		`{"uri":"http://api.ft.com/system/ISO-3166-1","alias":"ISO-3166-1"},`+
+		`{"uri":"http://api.ft.com/system/GEONAMES","alias":"GEONAMES"},`+
+		`{"uri":"http://api.ft.com/system/WIKIDATA","alias":"WIKIDATA"},`+
+		`{"uri":"http://api.ft.com/system/DBPEDIA","alias":"DBPEDIA"},`+
+		`{"uri":"http://api.ft.com/system/NAICS","alias":"NAICS"}]}`, w.Body.String())
+}
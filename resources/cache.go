@@ -0,0 +1,97 @@
+package resources
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+)
+
+type cacheStatsResponse struct {
+	Caches map[string]concepts.CacheStats `json:"caches"`
+}
+
+type cachePurgeResponse struct {
+	Purged map[string]int `json:"purged"`
+}
+
+// CacheStats reports the size, hit ratio and evictions of the provided caches
+func CacheStats(caches ...concepts.Cache) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+
+		resp := cacheStatsResponse{Caches: make(map[string]concepts.CacheStats)}
+		for _, c := range caches {
+			resp.Caches[c.Name()] = c.Stats()
+		}
+
+		writeOK(w, resp)
+	}
+}
+
+// PurgeCache evicts the requested ids and/or authority from the provided caches, or flushes them completely if neither is given.
+// The concepts concorded by the evicted concordances are evicted as well, so they are looked up again.
+func PurgeCache(caches ...concepts.Cache) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+
+		authority := concepts.NoAuthority
+		authorityParam, foundAuthority := getMultiValuedParam(req, "authority")
+		if foundAuthority {
+			if len(authorityParam) != 1 {
+				writeJSON("Please provide one value for 'authority' query parameter", http.StatusBadRequest, w)
+				return
+			}
+			if authorityParam[0] == "" {
+				writeJSON("Please provide a non-empty 'authority' query parameter", http.StatusBadRequest, w)
+				return
+			}
+			var supported bool
+			if authority, supported = concepts.ResolveAuthority(authorityParam[0]); !supported {
+				writeJSON(unsupportedAuthorityError(authorityParam[0]).Error(), http.StatusBadRequest, w)
+				return
+			}
+		}
+
+		ids, foundIDs := getMultiValuedParam(req, "ids")
+		for _, id := range ids {
+			if id == "" {
+				writeJSON("Please provide non-empty ids to purge, using the 'ids' query parameter", http.StatusBadRequest, w)
+				return
+			}
+		}
+
+		resp := cachePurgeResponse{Purged: make(map[string]int)}
+		if !foundAuthority && !foundIDs {
+			for _, c := range caches {
+				resp.Purged[c.Name()] = c.Flush()
+			}
+			writeOK(w, resp)
+			return
+		}
+
+		// the concepts of the purged concordances are collected before they are evicted, so they can be purged from the other caches too
+		var canonicalUUIDs []string
+		for _, c := range caches {
+			if concordances, ok := c.(concepts.ConcordancesCache); ok {
+				canonicalUUIDs = append(canonicalUUIDs, concordances.CanonicalUUIDs(authority, ids...)...)
+			}
+		}
+
+		for _, c := range caches {
+			resp.Purged[c.Name()] = c.Purge(authority, ids...)
+			if _, concordances := c.(concepts.ConcordancesCache); !concordances && len(canonicalUUIDs) > 0 {
+				resp.Purged[c.Name()] += c.Purge(concepts.NoAuthority, canonicalUUIDs...)
+			}
+		}
+
+		writeOK(w, resp)
+	}
+}
+
+// writeOK writes the response as JSON with a 200 status
+func writeOK(w http.ResponseWriter, resp interface{}) {
+	jsonBytes, _ := json.Marshal(resp)
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
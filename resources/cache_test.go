@@ -0,0 +1,165 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCacheStats(t *testing.T) {
+	search := new(mockCache)
+	search.On("Name").Return("concept-search")
+	search.On("Stats").Return(concepts.CacheStats{Size: 2, Hits: 3, Misses: 1, HitRatio: 0.75, Evictions: 4})
+
+	concordances := new(mockCache)
+	concordances.On("Name").Return("public-concordances")
+	concordances.On("Stats").Return(concepts.CacheStats{})
+
+	req := httptest.NewRequest("GET", "/__cache", nil)
+	w := httptest.NewRecorder()
+
+	CacheStats(search, concordances)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"caches":{"concept-search":{"size":2,"hits":3,"misses":1,"hitRatio":0.75,"evictions":4},"public-concordances":{"size":0,"hits":0,"misses":0,"hitRatio":0,"evictions":0}}}`, w.Body.String())
+
+	search.AssertExpectations(t)
+	concordances.AssertExpectations(t)
+}
+
+func TestPurgeCacheFlush(t *testing.T) {
+	search := new(mockCache)
+	search.On("Name").Return("concept-search")
+	search.On("Flush").Return(3)
+
+	req := httptest.NewRequest("DELETE", "/__cache", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache(search)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"purged":{"concept-search":3}}`, w.Body.String())
+
+	search.AssertExpectations(t)
+}
+
+func TestPurgeCacheByIDs(t *testing.T) {
+	search := new(mockCache)
+	search.On("Name").Return("concept-search")
+	search.On("Purge", concepts.NoAuthority, []string{"uuid-1", "uuid-2"}).Return(2)
+
+	concordances := new(mockCache)
+	concordances.On("Name").Return("public-concordances")
+	concordances.On("Purge", concepts.NoAuthority, []string{"uuid-1", "uuid-2"}).Return(1)
+
+	req := httptest.NewRequest("DELETE", "/__cache?ids=uuid-1&ids=uuid-2", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache(search, concordances)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"purged":{"concept-search":2,"public-concordances":1}}`, w.Body.String())
+
+	search.AssertExpectations(t)
+	concordances.AssertExpectations(t)
+}
+
+func TestPurgeCacheByAuthority(t *testing.T) {
+	concordances := new(mockCache)
+	concordances.On("Name").Return("public-concordances")
+	concordances.On("Purge", "http://api.ft.com/system/FT-TME", []string(nil)).Return(5)
+
+	req := httptest.NewRequest("DELETE", "/__cache?authority=http://api.ft.com/system/FT-TME", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache(concordances)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"purged":{"public-concordances":5}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+}
+
+func TestPurgeCacheByAuthorityPurgesConcordedConcepts(t *testing.T) {
+	concordances := new(mockConcordances)
+	concordances.On("GetConcordances", "tid_TestPurgeCacheByAuthorityPurgesConcordedConcepts", concepts.TMEAuthority, []string{"tme-1", "tme-2"}).
+		Return(map[string][]concepts.Identifier{
+			"uuid-1": {{Authority: concepts.TMEAuthority, IdentifierValue: "tme-1"}},
+			"uuid-2": {{Authority: concepts.TMEAuthority, IdentifierValue: "tme-2"}},
+		}, nil)
+	search := new(mockSearch)
+	search.On("ByIDs", "tid_TestPurgeCacheByAuthorityPurgesConcordedConcepts", []string{"uuid-1", "uuid-2"}).
+		Return(map[string]concepts.Concept{"uuid-1": {ID: "uuid-1"}, "uuid-2": {ID: "uuid-2"}}, nil)
+
+	cachedConcordances := concepts.NewCachedConcordances(concordances, time.Minute, 0, 10)
+	cachedSearch := concepts.NewCachedSearch(search, time.Minute, time.Minute, 0, 10)
+	_, err := cachedConcordances.GetConcordances(context.Background(), "tid_TestPurgeCacheByAuthorityPurgesConcordedConcepts", concepts.TMEAuthority, "tme-1", "tme-2")
+	assert.NoError(t, err)
+	_, err = cachedSearch.ByIDs(context.Background(), "tid_TestPurgeCacheByAuthorityPurgesConcordedConcepts", "uuid-1", "uuid-2")
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("DELETE", "/__cache?authority=tme&ids=tme-1", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache(cachedSearch, cachedConcordances)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"purged":{"concept-search":1,"public-concordances":1}}`, w.Body.String())
+	assert.Equal(t, 1, cachedSearch.Stats().Size)
+	assert.Equal(t, 1, cachedConcordances.Stats().Size)
+}
+
+func TestPurgeCacheByAuthorityAlias(t *testing.T) {
+	concordances := new(mockCache)
+	concordances.On("Name").Return("public-concordances")
+	concordances.On("Purge", concepts.TMEAuthority, []string(nil)).Return(5)
+
+	req := httptest.NewRequest("DELETE", "/__cache?authority=tme", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache(concordances)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"purged":{"public-concordances":5}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+}
+
+func TestPurgeCacheUnsupportedAuthority(t *testing.T) {
+	concordances := new(mockCache)
+
+	req := httptest.NewRequest("DELETE", "/__cache?authority=http://api.ft.com/system/UNKNOWN", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache(concordances)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	concordances.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+}
+
+func TestPurgeCacheEmptyAuthority(t *testing.T) {
+	req := httptest.NewRequest("DELETE", "/__cache?authority=", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache()(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide a non-empty 'authority' query parameter"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestPurgeCacheEmptyIDs(t *testing.T) {
+	req := httptest.NewRequest("DELETE", "/__cache?ids=", nil)
+	w := httptest.NewRecorder()
+
+	PurgeCache()(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide non-empty ids to purge, using the 'ids' query parameter"}`, strings.TrimSpace(w.Body.String()))
+}
@@ -0,0 +1,90 @@
+package resources
+
+import (
+	"net/http"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+)
+
+type conceptIdentifiersResponse struct {
+	Concept concepts.Concept `json:"concept"`
+	// Identifiers maps each authority to the ids it has for the concept
+	Identifiers map[string][]string `json:"identifiers"`
+}
+
+// ConceptIdentifiers returns the concept with the canonical uuid given by the 'id' path parameter, along with every identifier concorded to it grouped by authority
+func ConceptIdentifiers(concordances concepts.Concordances, search concepts.Search) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		tid := tidutils.GetTransactionIDFromRequest(req)
+
+		uuid := concepts.NormaliseID(vestigo.Param(req, "id")) // shares the parameter name of the single concept route, which vestigo requires
+		if !concepts.IsValidID(concepts.UPPAuthority, uuid) {
+			writeJSON("Please provide a valid concept uuid", http.StatusBadRequest, w)
+			return
+		}
+		fields := getFieldsParam(req)
+		if err := validateFields(fields); err != nil {
+			writeJSON(err.Error(), http.StatusBadRequest, w)
+			return
+		}
+
+		concordancesCtx, cancel := withBudgetShare(req.Context(), concordancesBudgetShare)
+		defer cancel()
+
+		identifiers, err := concordances.GetConcordances(concordancesCtx, tid, concepts.NoAuthority, uuid)
+		if err != nil {
+			setRetryAfter(w, err)
+			writeJSON("Public Concordances request failed, please try again", http.StatusServiceUnavailable, w)
+			return
+		}
+
+		canonicalUUID, found := canonicalUUIDOf(uuid, identifiers)
+		if !found {
+			writeJSON("No concept found with uuid "+uuid, http.StatusNotFound, w)
+			return
+		}
+
+		searchedConcepts, err := search.ByIDs(req.Context(), tid, canonicalUUID)
+		if err != nil {
+			setRetryAfter(w, err)
+			writeJSON("Concept Search request failed, please try again", http.StatusServiceUnavailable, w)
+			return
+		}
+
+		concept, found := searchedConcepts[canonicalUUID]
+		if !found {
+			writeJSON("No concept found with uuid "+uuid, http.StatusNotFound, w)
+			return
+		}
+		if concept.Stale {
+			w.Header().Set("Warning", staleWarning)
+		}
+
+		resp := conceptIdentifiersResponse{Concept: concept.WithFields(fields...), Identifiers: make(map[string][]string)}
+		for _, identifier := range identifiers[canonicalUUID] {
+			resp.Identifiers[identifier.Authority] = append(resp.Identifiers[identifier.Authority], identifier.IdentifierValue)
+		}
+
+		writeOK(w, resp)
+	}
+}
+
+// canonicalUUIDOf returns the canonical uuid of the concept the uuid is concorded to, which is usually the uuid itself
+func canonicalUUIDOf(uuid string, identifiers map[string][]concepts.Identifier) (string, bool) {
+	if _, found := identifiers[uuid]; found {
+		return uuid, true
+	}
+
+	requested := requestedID{key: uuid, authority: concepts.NoAuthority, id: uuid}
+	for canonicalUUID, concorded := range identifiers {
+		for _, identifier := range concorded {
+			if requested.concordsTo(identifier) {
+				return canonicalUUID, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,199 @@
+package resources
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveConceptIdentifiers(concordances concepts.Concordances, search concepts.Search, req *http.Request) *httptest.ResponseRecorder {
+	r := vestigo.NewRouter()
+	r.Get("/internalconcordances/:id/identifiers", ConceptIdentifiers(concordances, search))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestConceptIdentifiers(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9/identifiers", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiers")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiers", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+				{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+				{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"},
+				{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="},
+			},
+		}, nil)
+	search.On("ByIDs", "tid_TestConceptIdentifiers", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}}, nil)
+
+	w := serveConceptIdentifiers(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concept":{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump"},"identifiers":{`+
+		`"http://api.ft.com/system/FT-TME":["TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="],"http://api.ft.com/system/UPP":["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"]}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersOfConcordedUUID(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e/identifiers", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiersOfConcordedUUID")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiersOfConcordedUUID", "", []string{"9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+				{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+				{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"},
+			},
+		}, nil)
+	search.On("ByIDs", "tid_TestConceptIdentifiersOfConcordedUUID", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump", Stale: true}}, nil)
+
+	w := serveConceptIdentifiers(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, staleWarning, w.Header().Get("Warning"))
+	assert.Equal(t, `{"concept":{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump"},"identifiers":{`+
+		`"http://api.ft.com/system/UPP":["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"]}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersNoConcordance(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f/identifiers", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiersNoConcordance")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiersNoConcordance", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{}, nil)
+
+	w := serveConceptIdentifiers(concordances, nil, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"No concept found with uuid 58dbb3e3-fc59-5d96-b796-232283dc3a2f"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersMissingFromSearch(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f/identifiers", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiersMissingFromSearch")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiersMissingFromSearch", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}}}, nil)
+	search.On("ByIDs", "tid_TestConceptIdentifiersMissingFromSearch", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string]concepts.Concept{}, nil)
+
+	w := serveConceptIdentifiers(concordances, search, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"No concept found with uuid 58dbb3e3-fc59-5d96-b796-232283dc3a2f"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersConcordancesFail(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f/identifiers", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiersConcordancesFail")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiersConcordancesFail", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier(nil), &concepts.CircuitOpenError{Upstream: "public-concordances-api", RetryAfter: 2 * time.Second})
+
+	w := serveConceptIdentifiers(concordances, nil, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, `{"message":"Public Concordances request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersSearchFails(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f/identifiers", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiersSearchFails")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiersSearchFails", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}}}, nil)
+	search.On("ByIDs", "tid_TestConceptIdentifiersSearchFails", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string]concepts.Concept(nil), errComputerSaysNo)
+
+	w := serveConceptIdentifiers(concordances, search, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, `{"message":"Concept Search request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersInvalidUUID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/internalconcordances/not-a-uuid/identifiers", nil)
+
+	w := serveConceptIdentifiers(nil, nil, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide a valid concept uuid"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestConceptIdentifiersSelectsFields(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9/identifiers?fields=scopeNote", nil)
+	req.Header.Add("X-Request-Id", "tid_TestConceptIdentifiersSelectsFields")
+
+	concordances.On("GetConcordances", "tid_TestConceptIdentifiersSelectsFields", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}},
+		}, nil)
+	search.On("ByIDs", "tid_TestConceptIdentifiersSelectsFields", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump", Aliases: []string{"Trump"}, ScopeNote: "45th President of the United States"},
+		}, nil)
+
+	w := serveConceptIdentifiers(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concept":{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump","scopeNote":"45th President of the United States"},"identifiers":{`+
+		`"http://api.ft.com/system/UPP":["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"]}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestConceptIdentifiersUnsupportedField(t *testing.T) {
+	req := httptest.NewRequest("GET", "/internalconcordances/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9/identifiers?fields=unknown", nil)
+
+	w := serveConceptIdentifiers(nil, nil, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Please provide supported fields instead of 'unknown'")
+}
@@ -0,0 +1,86 @@
+package resources
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+	"github.com/husobee/vestigo"
+)
+
+type notFoundResponse struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+// InternalConcordance concords the single id given by the 'id' path parameter, and returns its concept directly.
+// When an id looked up without an authority is concorded to a different canonical uuid, the concept is returned
+// with a Content-Location of the canonical uuid, rather than a redirect, as concordances change over time.
+func InternalConcordance(concordances concepts.Concordances, search concepts.Search) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+
+		id := vestigo.Param(req, "id")
+		if id == "" {
+			writeJSON("Please provide a non-empty id to concord", http.StatusBadRequest, w)
+			return
+		}
+
+		params := newConcordanceRequest("'id' path parameter")
+		if err := parseQueryOptions(req, &params); err != nil {
+			writeJSON(err.Error(), http.StatusBadRequest, w)
+			return
+		}
+		params.IDs = []string{id}
+		params.Partial = false // a single id either resolves or fails
+
+		resp, stale, concordanceErr := resolve(req, concordances, search, params)
+		if concordanceErr != nil {
+			concordanceErr.write(w)
+			return
+		}
+
+		concept, found := resp.Concepts[id]
+		if !found {
+			writeNotFound(w, id, resp.NotFound)
+			return
+		}
+
+		if stale {
+			w.Header().Set("Warning", staleWarning)
+		}
+
+		if canonicalUUID := concept.UUID(); params.Authority == concepts.NoAuthority && canonicalUUID != concepts.NormaliseID(id) {
+			w.Header().Set("Content-Location", canonicalLocation(req, canonicalUUID))
+		}
+
+		jsonBytes, _ := json.Marshal(concept)
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonBytes)
+	}
+}
+
+// canonicalLocation returns the path of the single concept route for the canonical uuid, keeping the query of the request
+func canonicalLocation(req *http.Request, canonicalUUID string) string {
+	location := "/internalconcordances/" + canonicalUUID
+	if query := req.URL.Query(); len(query) > 0 {
+		query.Del(":id") // vestigo adds path parameters to the query
+		if encoded := query.Encode(); encoded != "" {
+			location += "?" + encoded
+		}
+	}
+	return location
+}
+
+func writeNotFound(w http.ResponseWriter, id string, missing []notFound) {
+	resp := notFoundResponse{Message: "No concept found for id " + id, Reason: reasonNoConcordance}
+	for _, m := range missing {
+		if m.ID == id {
+			resp.Reason = m.Reason
+		}
+	}
+
+	jsonBytes, _ := json.Marshal(resp)
+	w.WriteHeader(http.StatusNotFound)
+	w.Write(jsonBytes)
+}
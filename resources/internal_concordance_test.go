@@ -0,0 +1,219 @@
+package resources
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/internal-concordances/concepts"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveInternalConcordance(concordances concepts.Concordances, search concepts.Search, req *http.Request) *httptest.ResponseRecorder {
+	r := vestigo.NewRouter()
+	r.Get("/internalconcordances/:id", InternalConcordance(concordances, search))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestInternalConcordance(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordance")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordance", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordance", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string]concepts.Concept{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f", PrefLabel: "Donald Trump"}}, nil)
+
+	w := serveInternalConcordance(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Location"))
+	assert.Equal(t, `{"id":"http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f","prefLabel":"Donald Trump"}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordanceWithAuthority(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=?authority=http://api.ft.com/system/FT-TME", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceWithAuthority")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceWithAuthority", "http://api.ft.com/system/FT-TME", []string{"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}).
+		Return(map[string][]concepts.Identifier{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordanceWithAuthority", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}}, nil)
+
+	w := serveInternalConcordance(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Location"))
+	assert.Equal(t, `{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump"}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordanceLocatesCanonicalUUID(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e?include_identifiers=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceLocatesCanonicalUUID")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceLocatesCanonicalUUID", "", []string{"9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"}).
+		Return(map[string][]concepts.Identifier{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+			{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+			{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"},
+		}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordanceLocatesCanonicalUUID", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}}, nil)
+
+	w := serveInternalConcordance(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/internalconcordances/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9?include_identifiers=true", w.Header().Get("Content-Location"))
+	assert.Equal(t, `{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump","identifiers":[`+
+		`{"identifierValue":"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","authority":"http://api.ft.com/system/UPP"},`+
+		`{"identifierValue":"9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e","authority":"http://api.ft.com/system/UPP"}]}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordanceLocatesSuccessor(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/0ccabb85-27f8-5d90-89c9-5744555b41b4?resolve_deprecated=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceLocatesSuccessor")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceLocatesSuccessor", "", []string{"0ccabb85-27f8-5d90-89c9-5744555b41b4"}).
+		Return(map[string][]concepts.Identifier{"0ccabb85-27f8-5d90-89c9-5744555b41b4": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "0ccabb85-27f8-5d90-89c9-5744555b41b4"}}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordanceLocatesSuccessor", []string{"0ccabb85-27f8-5d90-89c9-5744555b41b4"}).
+		Return(map[string]concepts.Concept{"0ccabb85-27f8-5d90-89c9-5744555b41b4": {ID: "http://www.ft.com/thing/0ccabb85-27f8-5d90-89c9-5744555b41b4", PrefLabel: "Superseded", IsDeprecated: true, SupersededBy: "f78646f5-fb3a-5a20-aa60-c7c33b2a10e7"}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordanceLocatesSuccessor", []string{"f78646f5-fb3a-5a20-aa60-c7c33b2a10e7"}).
+		Return(map[string]concepts.Concept{"f78646f5-fb3a-5a20-aa60-c7c33b2a10e7": {ID: "http://www.ft.com/thing/f78646f5-fb3a-5a20-aa60-c7c33b2a10e7", PrefLabel: "Successor"}}, nil)
+
+	w := serveInternalConcordance(concordances, search, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/internalconcordances/f78646f5-fb3a-5a20-aa60-c7c33b2a10e7?resolve_deprecated=true", w.Header().Get("Content-Location"))
+	assert.Equal(t, `{"id":"http://www.ft.com/thing/f78646f5-fb3a-5a20-aa60-c7c33b2a10e7","prefLabel":"Successor"}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordanceNotFound(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceNotFound")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceNotFound", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{}, nil)
+
+	w := serveInternalConcordance(concordances, nil, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"No concept found for id 58dbb3e3-fc59-5d96-b796-232283dc3a2f","reason":"noConcordance"}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+}
+
+func TestInternalConcordanceDeprecatedNotFound(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f?include_deprecated=false", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceDeprecatedNotFound")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceDeprecatedNotFound", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordanceDeprecatedNotFound", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string]concepts.Concept{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f", PrefLabel: "Donald Trump", IsDeprecated: true}}, nil)
+
+	w := serveInternalConcordance(concordances, search, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"No concept found for id 58dbb3e3-fc59-5d96-b796-232283dc3a2f","reason":"deprecated"}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordanceTypeFilteredNotFound(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/12381f53-170a-555c-ac0f-32d49b2b1921?type=Person", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceTypeFilteredNotFound")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceTypeFilteredNotFound", "", []string{"12381f53-170a-555c-ac0f-32d49b2b1921"}).
+		Return(map[string][]concepts.Identifier{"12381f53-170a-555c-ac0f-32d49b2b1921": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "12381f53-170a-555c-ac0f-32d49b2b1921"}}}, nil)
+	search.On("ByIDs", "tid_TestInternalConcordanceTypeFilteredNotFound", []string{"12381f53-170a-555c-ac0f-32d49b2b1921"}).
+		Return(map[string]concepts.Concept{"12381f53-170a-555c-ac0f-32d49b2b1921": {ID: "http://www.ft.com/thing/12381f53-170a-555c-ac0f-32d49b2b1921", PrefLabel: "Companies", Type: "http://www.ft.com/ontology/Section"}}, nil)
+
+	w := serveInternalConcordance(concordances, search, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"No concept found for id 12381f53-170a-555c-ac0f-32d49b2b1921","reason":"typeFiltered"}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordanceFails(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f?partial=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestInternalConcordanceFails")
+
+	concordances.On("GetConcordances", "tid_TestInternalConcordanceFails", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier(nil), errComputerSaysNo)
+
+	w := serveInternalConcordance(concordances, nil, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, `{"message":"Public Concordances request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+}
+
+func TestInternalConcordanceInvalidOptions(t *testing.T) {
+	req := httptest.NewRequest("GET", "/internalconcordances/58dbb3e3-fc59-5d96-b796-232283dc3a2f?include_deprecated=maybe", nil)
+
+	w := serveInternalConcordance(nil, nil, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide a valid boolean for 'include_deprecated' query parameter"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestInternalConcordanceInvalidID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/internalconcordances/not-a-uuid", nil)
+
+	w := serveInternalConcordance(nil, nil, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide ids in the format of their authority","invalidIds":["not-a-uuid"]}`, w.Body.String())
+}
+
+func TestInternalConcordanceLenientInvalidID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/internalconcordances/not-a-uuid?lenient=true", nil)
+
+	w := serveInternalConcordance(new(mockConcordances), nil, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"message":"No concept found for id not-a-uuid","reason":"invalidId"}`, w.Body.String())
+}
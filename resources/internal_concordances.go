@@ -1,86 +1,562 @@
 package resources
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
-
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Financial-Times/internal-concordances/concepts"
 	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	staleWarning = `110 - "Response is Stale"`
+	// authoritySeparator separates the authority from the id of identifiers which carry their own authority
+	authoritySeparator = ":"
+	// concordancesBudgetShare is the share of the remaining request budget given to public concordances, concept search is given the rest
+	concordancesBudgetShare = 0.5
+	// ftAuthorFilteredMetric counts the requested ids left out of responses by ft_author_only
+	ftAuthorFilteredMetric = "ft-author-only.filtered"
+	// maxBatchBodyBytes bounds the size of the JSON body of batch requests
+	maxBatchBodyBytes = 1 << 20
+	// maxBatchIDs bounds the number of ids and identifiers of batch requests
+	maxBatchIDs = 5000
 )
 
 type internalConcordancesResponse struct {
 	Concepts map[string]concepts.Concept `json:"concepts"`
+	// Errors maps the requested ids which could not be resolved to the reason why, only in partial responses
+	Errors map[string]string `json:"errors,omitempty"`
+	// NotFound lists the requested ids which resolved to no concept, and why
+	NotFound []notFound `json:"notFound,omitempty"`
+	// Replaced maps the requested ids whose deprecated concept was replaced by its successor to the id of the deprecated concept
+	Replaced map[string]string `json:"replaced,omitempty"`
+}
+
+type notFound struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+const (
+	reasonNoConcordance     = "noConcordance"
+	reasonMissingFromSearch = "missingFromSearch"
+	reasonDeprecated        = "deprecated"
+	reasonInvalidID         = "invalidId"
+	reasonTypeFiltered      = "typeFiltered"
+	reasonNotFTAuthor       = "notFTAuthor"
+)
+
+// concordanceRequest holds the ids to concord and the options of the lookup, whether provided as query parameters or a JSON body
+type concordanceRequest struct {
+	IDs       []string `json:"ids"`
+	Authority string   `json:"authority"`
+	// Identifiers are ids which each carry their own authority, so several authorities can be concorded at once
+	Identifiers       []concepts.Identifier `json:"identifiers"`
+	IncludeDeprecated bool                  `json:"include_deprecated"`
+	// ResolveDeprecated replaces deprecated concepts with their successor, when they have one
+	ResolveDeprecated  bool `json:"resolve_deprecated"`
+	Partial            bool `json:"partial"`
+	IncludeIdentifiers bool `json:"include_identifiers"`
+	// Lenient reports ids which are not in the format of their authority as not found, rather than rejecting the request
+	Lenient bool `json:"lenient"`
+	// Fields are the optional concept fields to return, which are left out by default
+	Fields []string `json:"fields"`
+	// Types restricts the concepts returned to those of any of the types, given by ontology URI or short name
+	Types []string `json:"type"`
+	// FTAuthorOnly restricts the concepts returned to FT authors
+	FTAuthorOnly bool `json:"ft_author_only"`
+
+	// idsSource describes how the ids were provided, for error messages
+	idsSource string
+}
+
+func newConcordanceRequest(idsSource string) concordanceRequest {
+	return concordanceRequest{Authority: concepts.NoAuthority, IncludeDeprecated: true, idsSource: idsSource}
+}
+
+// validate checks the identifiers, fields and types of the request, however they were provided
+func (r concordanceRequest) validate() error {
+	for _, identifier := range r.Identifiers {
+		if identifier.Authority == "" || identifier.IdentifierValue == "" {
+			return errors.New("Please provide a non-empty authority and identifierValue for each of the 'identifiers'")
+		}
+		if _, supported := concepts.ResolveAuthority(identifier.Authority); !supported {
+			return unsupportedAuthorityError(identifier.Authority)
+		}
+	}
+	if err := validateFields(r.Fields); err != nil {
+		return err
+	}
+	for _, t := range r.Types {
+		if t == "" {
+			return errors.New("Please provide non-empty 'type' values")
+		}
+	}
+	return nil
+}
+
+func (r concordanceRequest) filter() conceptFilter {
+	return conceptFilter{includeDeprecated: r.IncludeDeprecated, types: r.Types, ftAuthorOnly: r.FTAuthorOnly}
+}
+
+// conceptFilter decides which of the concorded concepts are returned
+type conceptFilter struct {
+	includeDeprecated bool
+	types             []string
+	ftAuthorOnly      bool
+}
+
+// excludes returns the reason the concept is left out of the response, if it is
+func (f conceptFilter) excludes(concept concepts.Concept) (string, bool) {
+	switch {
+	case !f.includeDeprecated && concept.IsDeprecated:
+		return reasonDeprecated, true
+	case !hasAnyType(concept, f.types):
+		return reasonTypeFiltered, true
+	case f.ftAuthorOnly && (concept.IsFTAuthor == nil || !*concept.IsFTAuthor):
+		return reasonNotFTAuthor, true
+	}
+	return "", false
+}
+
+// requestedIDs returns every id to concord in request order, keyed in the response exactly as it was requested.
+// Thing URIs are looked up by their uuid.
+func (r concordanceRequest) requestedIDs() []requestedID {
+	requested := make([]requestedID, 0, len(r.IDs)+len(r.Identifiers))
+	for _, id := range r.IDs {
+		requested = append(requested, newRequestedID(id, r.Authority, id))
+	}
+	for _, identifier := range r.Identifiers {
+		authority, _ := concepts.ResolveAuthority(identifier.Authority) // already validated when the request was parsed
+		requested = append(requested, newRequestedID(identifier.Authority+authoritySeparator+identifier.IdentifierValue, authority, identifier.IdentifierValue))
+	}
+	return requested
+}
+
+func newRequestedID(key, authority, id string) requestedID {
+	id = concepts.NormaliseID(id)
+	return requestedID{key: key, authority: authority, id: id, invalid: id != "" && !concepts.IsValidID(authority, id)}
+}
+
+// requestedID is an id to concord, ids given with their own authority are keyed as authority:id in the response
+type requestedID struct {
+	key       string
+	authority string
+	id        string
+	// invalid is set when the id is not in the format of its authority, so is not looked up
+	invalid bool
+}
+
+// concordsTo reports whether the requested id is the given identifier, ids requested without an authority match any authority
+func (r requestedID) concordsTo(identifier concepts.Identifier) bool {
+	return !r.invalid && identifier.IdentifierValue == r.id && (r.authority == concepts.NoAuthority || identifier.Authority == r.authority)
+}
+
+// requestedIndex holds the requested ids by id, so those concorded to an identifier are found without scanning every requested id.
+// Invalid ids concord to nothing, so are left out.
+type requestedIndex map[string][]requestedID
+
+func newRequestedIndex(requested []requestedID) requestedIndex {
+	index := make(requestedIndex)
+	for _, r := range requested {
+		if !r.invalid {
+			index[r.id] = append(index[r.id], r)
+		}
+	}
+	return index
+}
+
+func idsByAuthority(requested []requestedID) map[string][]string {
+	ids := make(map[string][]string)
+	for _, r := range requested {
+		if !r.invalid {
+			ids[r.authority] = append(ids[r.authority], r.id)
+		}
+	}
+	return ids
+}
+
+// invalidIDs returns the keys of the requested ids which are not in the format of their authority
+func invalidIDs(requested []requestedID) []string {
+	var invalid []string
+	for _, r := range requested {
+		if r.invalid {
+			invalid = append(invalid, r.key)
+		}
+	}
+	return invalid
 }
 
 // InternalConcordances concords provided uuids, and enriches them with concept model
 func InternalConcordances(concordances concepts.Concordances, search concepts.Search) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
-		tid := tidutils.GetTransactionIDFromRequest(req)
-
-		authority := concepts.NoAuthority
-		authorityParam, foundAuthority := getMultiValuedParam(req, "authority")
-		if foundAuthority {
-			if len(authorityParam) != 1 {
-				writeJSON("Please provide one value for 'authority' query parameter", http.StatusBadRequest, w)
-				return
-			}
-			authority = authorityParam[0]
-			if authority == "" {
-				writeJSON("Please provide a non-empty 'authority' query parameter", http.StatusBadRequest, w)
-				return
-			}
+
+		params, err := parseConcordanceQuery(req)
+		if err != nil {
+			writeJSON(err.Error(), http.StatusBadRequest, w)
+			return
 		}
-		ids, idsFound := getMultiValuedParam(req, "ids")
-		if !idsFound {
-			writeJSON("Please provide ids to concord, using the 'ids' query parameter", http.StatusBadRequest, w)
+
+		concord(w, req, concordances, search, params)
+	}
+}
+
+// InternalConcordancesBatch concords the ids provided in a JSON body, for batches too large for the query string
+func InternalConcordancesBatch(concordances concepts.Concordances, search concepts.Search) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+
+		req.Body = http.MaxBytesReader(w, req.Body, maxBatchBodyBytes)
+		params, err := parseConcordanceBody(req)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSON(err.Error(), http.StatusRequestEntityTooLarge, w)
+			return
+		}
+		if err != nil {
+			writeJSON(err.Error(), http.StatusBadRequest, w)
 			return
 		}
 
-		includeDeprecated := true
-		includeDeprecatedParam, foundIncludeDeprecated := getMultiValuedParam(req, "include_deprecated")
-		if foundIncludeDeprecated {
-			if len(includeDeprecatedParam) != 1 {
-				writeJSON("Please provide one value for 'include_deprecated' query parameter", http.StatusBadRequest, w)
-				return
-			}
-			includeDeprecatedValue, err := strconv.ParseBool(includeDeprecatedParam[0])
-			if err != nil {
-				writeJSON("Please provide a valid boolean for 'include_deprecated' query parameter", http.StatusBadRequest, w)
-				return
-			}
-			includeDeprecated = includeDeprecatedValue
+		concord(w, req, concordances, search, params)
+	}
+}
+
+func parseConcordanceQuery(req *http.Request) (concordanceRequest, error) {
+	params := newConcordanceRequest("'ids' query parameter")
+
+	ids, idsFound := getMultiValuedParam(req, "ids")
+	identifiers, identifiersFound := getMultiValuedParam(req, "identifiers")
+	if !idsFound && !identifiersFound {
+		return params, errors.New("Please provide ids to concord, using the 'ids' query parameter")
+	}
+	params.IDs = ids
+
+	for _, value := range identifiers {
+		identifier, ok := splitAuthorityID(value)
+		if !ok {
+			return params, fmt.Errorf("Please provide each 'identifiers' query parameter as authority:id, not '%v'", value)
+		}
+		params.Identifiers = append(params.Identifiers, identifier)
+	}
+
+	err := parseQueryOptions(req, &params)
+	return params, err
+}
+
+// parseQueryOptions sets the authority and options of the lookup from the query parameters
+func parseQueryOptions(req *http.Request, params *concordanceRequest) error {
+	authorityParam, foundAuthority := getMultiValuedParam(req, "authority")
+	if foundAuthority {
+		if len(authorityParam) != 1 {
+			return errors.New("Please provide one value for 'authority' query parameter")
+		}
+		if authorityParam[0] == "" {
+			return errors.New("Please provide a non-empty 'authority' query parameter")
+		}
+		authority, supported := concepts.ResolveAuthority(authorityParam[0])
+		if !supported {
+			return unsupportedAuthorityError(authorityParam[0])
+		}
+		params.Authority = authority
+	}
+
+	var err error
+	if params.IncludeDeprecated, err = getBoolParam(req, "include_deprecated", true); err != nil {
+		return err
+	}
+	if params.ResolveDeprecated, err = getBoolParam(req, "resolve_deprecated", false); err != nil {
+		return err
+	}
+	if params.Partial, err = getBoolParam(req, "partial", false); err != nil {
+		return err
+	}
+	if params.IncludeIdentifiers, err = getBoolParam(req, "include_identifiers", false); err != nil {
+		return err
+	}
+	if params.Lenient, err = getBoolParam(req, "lenient", false); err != nil {
+		return err
+	}
+	if params.FTAuthorOnly, err = getBoolParam(req, "ft_author_only", false); err != nil {
+		return err
+	}
+	params.Types, _ = getMultiValuedParam(req, "type")
+	params.Fields = getFieldsParam(req)
+	return params.validate()
+}
+
+// getFieldsParam returns the concept fields selected by the 'fields' query parameter, given either repeatedly or comma separated
+func getFieldsParam(req *http.Request) []string {
+	values, _ := getMultiValuedParam(req, "fields")
+	var fields []string
+	for _, value := range values {
+		fields = append(fields, strings.Split(value, ",")...)
+	}
+	return fields
+}
+
+// validateFields checks that every field is an optional concept field, which are the only fields that may be selected
+func validateFields(fields []string) error {
+	for _, field := range fields {
+		if !concepts.IsOptionalField(field) {
+			return unsupportedFieldError(field)
 		}
-		identifiers, err := concordances.GetConcordances(tid, authority, ids...)
+	}
+	return nil
+}
+
+// unsupportedFieldError lists the optional fields of a concept, which are the only fields that may be selected
+func unsupportedFieldError(field string) error {
+	return fmt.Errorf("Please provide supported fields instead of '%v', any of %v", field, strings.Join(concepts.OptionalFields(), ", "))
+}
+
+func parseConcordanceBody(req *http.Request) (concordanceRequest, error) {
+	params := newConcordanceRequest("'ids' field")
+
+	dec := json.NewDecoder(req.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&params); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return params, fmt.Errorf("Please provide a JSON body of at most %d bytes: %w", tooLarge.Limit, err)
+		}
+		return params, fmt.Errorf("Please provide a valid JSON body: %v", err)
+	}
+	if len(params.IDs) == 0 && len(params.Identifiers) == 0 {
+		return params, errors.New("Please provide ids to concord, using the 'ids' field")
+	}
+	if len(params.IDs)+len(params.Identifiers) > maxBatchIDs {
+		return params, fmt.Errorf("Please provide at most %d ids and identifiers to concord", maxBatchIDs)
+	}
+	if err := params.validate(); err != nil {
+		return params, err
+	}
+
+	authority, supported := concepts.ResolveAuthority(params.Authority)
+	if !supported {
+		return params, unsupportedAuthorityError(params.Authority)
+	}
+	params.Authority = authority
+	return params, nil
+}
+
+// unsupportedAuthorityError lists the supported authorities, as either their alias or URI may be given
+func unsupportedAuthorityError(authority string) error {
+	var supported []string
+	for _, a := range concepts.Authorities() {
+		supported = append(supported, a.Alias+" ("+a.URI+")")
+	}
+	return fmt.Errorf("Please provide a supported authority instead of '%v', one of %v", authority, strings.Join(supported, ", "))
+}
+
+// splitAuthorityID splits an authority:id pair at the first colon which is not part of the authority's URI scheme
+func splitAuthorityID(value string) (concepts.Identifier, bool) {
+	for i := 0; i < len(value); i++ {
+		if value[i] != ':' || strings.HasPrefix(value[i+1:], "//") {
+			continue
+		}
+		identifier := concepts.Identifier{Authority: value[:i], IdentifierValue: value[i+1:]}
+		return identifier, identifier.Authority != "" && identifier.IdentifierValue != ""
+	}
+	return concepts.Identifier{}, false
+}
+
+// concord looks up the concepts concorded to the requested ids, and writes them along with the ids which could not be resolved
+func concord(w http.ResponseWriter, req *http.Request, concordances concepts.Concordances, search concepts.Search, params concordanceRequest) {
+	resp, stale, concordanceErr := resolve(req, concordances, search, params)
+	if concordanceErr != nil {
+		concordanceErr.write(w)
+		return
+	}
+
+	if stale {
+		w.Header().Set("Warning", staleWarning)
+	}
+	writeInternalConcordanceResponse(w, resp)
+}
+
+// resolve looks up the concepts concorded to the requested ids, along with the ids which could not be resolved and whether any concept is stale
+func resolve(req *http.Request, concordances concepts.Concordances, search concepts.Search, params concordanceRequest) (internalConcordancesResponse, bool, *concordanceError) {
+	tid := tidutils.GetTransactionIDFromRequest(req)
+
+	requested := params.requestedIDs()
+	if invalid := invalidIDs(requested); len(invalid) > 0 && !params.Lenient {
+		return internalConcordancesResponse{}, false, &concordanceError{status: http.StatusBadRequest, message: "Please provide ids in the format of their authority", invalidIDs: invalid}
+	}
+
+	byAuthority := idsByAuthority(requested)
+	index := newRequestedIndex(requested)
+	failures := make(map[string]string)
+
+	concordancesCtx, cancel := withBudgetShare(req.Context(), concordancesBudgetShare)
+	defer cancel()
+
+	identifiers, errs := concepts.GetConcordancesForAuthorities(concordancesCtx, concordances, tid, byAuthority)
+	for _, err := range errs {
 		if err == concepts.ErrConceptIDsAreEmpty {
-			writeJSON("Please provide non-empty ids to concord, using the 'ids' query parameter", http.StatusBadRequest, w)
-			return
+			return internalConcordancesResponse{}, false, &concordanceError{status: http.StatusBadRequest, message: "Please provide non-empty ids to concord, using the " + params.idsSource}
+		}
+	}
+
+	for authority, err := range errs {
+		if !params.Partial {
+			return internalConcordancesResponse{}, false, &concordanceError{status: http.StatusServiceUnavailable, message: "Public Concordances request failed, please try again", err: err}
+		}
+		for _, r := range requested {
+			if r.authority != authority {
+				continue
+			}
+			if failure := concepts.FailureFor(r.id, err); failure != nil {
+				failures[r.key] = "Public Concordances request failed: " + failure.Error()
+			}
 		}
+	}
 
+	conceptIdentifiers := identifiers
+	if params.IncludeIdentifiers && anyAuthority(byAuthority) && len(identifiers) > 0 {
+		// lookups by authority only return the requested identifiers, so every identifier is looked up by canonical uuid
+		var err error
+		conceptIdentifiers, err = concordances.GetConcordances(concordancesCtx, tid, concepts.NoAuthority, conceptIdentifiersToUUIDs(identifiers)...)
 		if err != nil {
-			writeJSON("Public Concordances request failed, please try again", http.StatusServiceUnavailable, w)
-			return
+			if !params.Partial {
+				return internalConcordancesResponse{}, false, &concordanceError{status: http.StatusServiceUnavailable, message: "Public Concordances request failed, please try again", err: err}
+			}
+			for uuid, concorded := range identifiers {
+				if failure := concepts.FailureFor(uuid, err); failure != nil {
+					for _, key := range requestedIDsConcordedTo(index, concorded) {
+						failures[key] = "Public Concordances request failed: " + failure.Error()
+					}
+					delete(identifiers, uuid)
+				}
+			}
 		}
+	}
 
-		if len(identifiers) == 0 { // all requested concepts were either deleted or missing
-			writeInternalConcordanceResponse(w, internalConcordancesResponse{Concepts: make(map[string]concepts.Concept)})
-			return
+	if len(identifiers) == 0 { // all requested concepts were either deleted, missing or failed
+		merged, missing := mergeConcordancesAndConcepts(requested, identifiers, nil, params.filter())
+		return internalConcordancesResponse{Concepts: merged, Errors: failures, NotFound: withoutFailures(missing, failures)}, false, nil
+	}
+
+	concordedUUIDs := conceptIdentifiersToUUIDs(identifiers)
+	searchedConcepts, err := search.ByIDs(req.Context(), tid, concordedUUIDs...)
+	if err != nil {
+		if !params.Partial {
+			return internalConcordancesResponse{}, false, &concordanceError{status: http.StatusServiceUnavailable, message: "Concept Search request failed, please try again", err: err}
+		}
+		for _, uuid := range concordedUUIDs {
+			if failure := concepts.FailureFor(uuid, err); failure != nil {
+				for _, key := range requestedIDsConcordedTo(index, identifiers[uuid]) {
+					failures[key] = "Concept Search request failed: " + failure.Error()
+				}
+			}
 		}
+	}
+
+	if params.IncludeIdentifiers {
+		addIdentifiers(searchedConcepts, conceptIdentifiers)
+	}
 
-		concordedUUIDs := conceptIdentifiersToUUIDs(identifiers)
-		concepts, err := search.ByIDs(tid, concordedUUIDs...)
+	var replaced map[string]string
+	if params.ResolveDeprecated {
+		resolved, deprecatedIDs, err := replaceDeprecated(req.Context(), concordances, search, tid, searchedConcepts, params.IncludeIdentifiers)
 		if err != nil {
-			writeJSON("Concept Search request failed, please try again", http.StatusServiceUnavailable, w)
+			if !params.Partial {
+				return internalConcordancesResponse{}, false, &concordanceError{status: http.StatusServiceUnavailable, message: "Successor lookup failed, please try again", err: err}
+			}
+			resolved = make(map[string]concepts.Concept)
+			for uuid, concept := range searchedConcepts {
+				if !concept.IsDeprecated {
+					resolved[uuid] = concept
+					continue
+				}
+				for _, key := range requestedIDsConcordedTo(index, identifiers[uuid]) {
+					failures[key] = "Successor lookup failed: " + err.Error()
+				}
+			}
+		}
+		searchedConcepts, replaced = resolved, deprecatedIDs
+	}
+
+	merged, missing := mergeConcordancesAndConcepts(requested, identifiers, searchedConcepts, params.filter())
+	for key, concept := range merged {
+		merged[key] = concept.WithFields(params.Fields...)
+	}
+	resp := internalConcordancesResponse{Concepts: merged, Errors: failures, NotFound: withoutFailures(missing, failures)}
+	countNotFTAuthors(resp.NotFound)
+
+	for uuid, deprecatedID := range replaced {
+		for _, key := range requestedIDsConcordedTo(index, identifiers[uuid]) {
+			if _, found := merged[key]; !found {
+				continue
+			}
+			if resp.Replaced == nil {
+				resp.Replaced = make(map[string]string)
+			}
+			resp.Replaced[key] = deprecatedID
+		}
+	}
+
+	return resp, anyStale(searchedConcepts), nil
+}
+
+// concordanceError is a failure to concord the requested ids, written to the client with the given status
+type concordanceError struct {
+	status  int
+	message string
+	// err is the upstream failure, if any
+	err error
+	// invalidIDs are the requested ids which are not in the format of their authority, if any
+	invalidIDs []string
+}
+
+type invalidIDsResponse struct {
+	Message    string   `json:"message"`
+	InvalidIDs []string `json:"invalidIds"`
+}
+
+func (e *concordanceError) write(w http.ResponseWriter) {
+	if e.err != nil {
+		setRetryAfter(w, e.err)
+	}
+	if len(e.invalidIDs) > 0 {
+		jsonBytes, _ := json.Marshal(invalidIDsResponse{Message: e.message, InvalidIDs: e.invalidIDs})
+		w.WriteHeader(e.status)
+		w.Write(jsonBytes)
+		return
+	}
+	writeJSON(e.message, e.status, w)
+}
+
+// WithRequestBudget bounds the context of every request by the given budget, which upstream calls share between them
+func WithRequestBudget(budget time.Duration, handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if budget <= 0 {
+			handler(w, req)
 			return
 		}
 
-		merged := mergeConcordancesAndConcepts(ids, identifiers, concepts, includeDeprecated)
-		resp := internalConcordancesResponse{Concepts: merged}
+		ctx, cancel := context.WithTimeout(req.Context(), budget)
+		defer cancel()
+		handler(w, req.WithContext(ctx))
+	}
+}
 
-		writeInternalConcordanceResponse(w, resp)
+// withBudgetShare returns a context whose deadline is the given share of the time remaining before the parent deadline
+func withBudgetShare(ctx context.Context, share float64) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, time.Duration(float64(time.Until(deadline))*share))
 }
 
 func writeInternalConcordanceResponse(w http.ResponseWriter, resp internalConcordancesResponse) {
@@ -89,25 +565,191 @@ func writeInternalConcordanceResponse(w http.ResponseWriter, resp internalConcor
 	w.Write(jsonBytes)
 }
 
-func mergeConcordancesAndConcepts(requestedIDs []string, identifiers map[string][]concepts.Identifier, searchedConcepts map[string]concepts.Concept, includeDeprecated bool) map[string]concepts.Concept {
+// mergeConcordancesAndConcepts maps each requested id to its concept, listing the requested ids which resolved to no concept in request order.
+// Concepts excluded by the filter are left out. Requested ids are indexed by id, so the merge is linear in the number of identifiers and requested ids.
+func mergeConcordancesAndConcepts(requestedIDs []requestedID, identifiers map[string][]concepts.Identifier, searchedConcepts map[string]concepts.Concept, filter conceptFilter) (map[string]concepts.Concept, []notFound) {
 	merged := make(map[string]concepts.Concept)
+	excluded := map[string]map[string]bool{reasonDeprecated: {}, reasonTypeFiltered: {}, reasonNotFTAuthor: {}}
+	index := newRequestedIndex(requestedIDs)
 
 	for uuid, concept := range searchedConcepts {
-		if !includeDeprecated && concept.IsDeprecated {
-			continue
-		}
+		reason, filtered := filter.excludes(concept)
 		concordances := identifiers[uuid]
 
 		for _, c := range concordances {
-			for _, requestedID := range requestedIDs {
-				if c.IdentifierValue == requestedID {
-					merged[requestedID] = concept
+			for _, requestedID := range index[c.IdentifierValue] {
+				if !requestedID.concordsTo(c) {
+					continue
 				}
+				if filtered {
+					excluded[reason][requestedID.key] = true
+					continue
+				}
+				merged[requestedID.key] = concept
+			}
+		}
+	}
+
+	concorded := make(map[string]bool)
+	for _, concordances := range identifiers {
+		for _, c := range concordances {
+			for _, requestedID := range index[c.IdentifierValue] {
+				if requestedID.concordsTo(c) {
+					concorded[requestedID.key] = true
+				}
+			}
+		}
+	}
+
+	var missing []notFound
+	reported := make(map[string]bool)
+	for _, requestedID := range requestedIDs {
+		key := requestedID.key
+		if _, found := merged[key]; found || requestedID.id == "" || reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		switch {
+		case requestedID.invalid:
+			missing = append(missing, notFound{ID: key, Reason: reasonInvalidID})
+		case excluded[reasonDeprecated][key]:
+			missing = append(missing, notFound{ID: key, Reason: reasonDeprecated})
+		case excluded[reasonTypeFiltered][key]:
+			missing = append(missing, notFound{ID: key, Reason: reasonTypeFiltered})
+		case excluded[reasonNotFTAuthor][key]:
+			missing = append(missing, notFound{ID: key, Reason: reasonNotFTAuthor})
+		case concorded[key]:
+			missing = append(missing, notFound{ID: key, Reason: reasonMissingFromSearch})
+		default:
+			missing = append(missing, notFound{ID: key, Reason: reasonNoConcordance})
+		}
+	}
+
+	return merged, missing
+}
+
+// replaceDeprecated returns the searched concepts with each deprecated concept replaced by its successor, when it has one,
+// along with the id of the deprecated concept each replaced uuid was searched for
+func replaceDeprecated(ctx context.Context, concordances concepts.Concordances, search concepts.Search, tid string, searchedConcepts map[string]concepts.Concept, includeIdentifiers bool) (map[string]concepts.Concept, map[string]string, error) {
+	deprecated := make(map[string]concepts.Concept)
+	for uuid, concept := range searchedConcepts {
+		if concept.IsDeprecated {
+			deprecated[uuid] = concept
+		}
+	}
+	if len(deprecated) == 0 {
+		return searchedConcepts, nil, nil
+	}
+
+	successors, err := concepts.GetSuccessors(ctx, search, tid, deprecated)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if includeIdentifiers && len(successors) > 0 {
+		var uuids []string
+		for _, successor := range successors {
+			uuids = append(uuids, successor.UUID())
+		}
+		identifiers, err := concordances.GetConcordances(ctx, tid, concepts.NoAuthority, uuids...)
+		if err != nil {
+			return nil, nil, err
+		}
+		for uuid, successor := range successors {
+			successor.Identifiers = identifiers[successor.UUID()]
+			successors[uuid] = successor
+		}
+	}
+
+	resolved := make(map[string]concepts.Concept)
+	replaced := make(map[string]string)
+	for uuid, concept := range searchedConcepts {
+		if successor, found := successors[uuid]; found {
+			resolved[uuid] = successor
+			replaced[uuid] = concept.ID
+			continue
+		}
+		resolved[uuid] = concept
+	}
+	return resolved, replaced, nil
+}
+
+// countNotFTAuthors counts the requested ids left out for not being FT authors
+func countNotFTAuthors(missing []notFound) {
+	var filtered int64
+	for _, m := range missing {
+		if m.Reason == reasonNotFTAuthor {
+			filtered++
+		}
+	}
+	if filtered > 0 {
+		metrics.GetOrRegisterCounter(ftAuthorFilteredMetric, metrics.DefaultRegistry).Inc(filtered)
+	}
+}
+
+// hasAnyType reports whether the concept is of any of the types, every concept is when no types are given
+func hasAnyType(concept concepts.Concept, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if concept.HasType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutFailures removes the ids which failed to resolve, as they are already reported as errors
+func withoutFailures(missing []notFound, failures map[string]string) []notFound {
+	var remaining []notFound
+	for _, m := range missing {
+		if _, failed := failures[m.ID]; !failed {
+			remaining = append(remaining, m)
+		}
+	}
+	return remaining
+}
+
+// addIdentifiers sets the identifiers of each searched concept to those concorded to its canonical uuid
+func addIdentifiers(searchedConcepts map[string]concepts.Concept, identifiers map[string][]concepts.Identifier) {
+	for uuid, concept := range searchedConcepts {
+		concept.Identifiers = identifiers[uuid]
+		searchedConcepts[uuid] = concept
+	}
+}
+
+func anyStale(searchedConcepts map[string]concepts.Concept) bool {
+	for _, concept := range searchedConcepts {
+		if concept.Stale {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedIDsConcordedTo returns the keys of the requested ids which are any of the identifiers
+func requestedIDsConcordedTo(index requestedIndex, identifiers []concepts.Identifier) []string {
+	var concorded []string
+	for _, c := range identifiers {
+		for _, requestedID := range index[c.IdentifierValue] {
+			if requestedID.concordsTo(c) {
+				concorded = append(concorded, requestedID.key)
 			}
 		}
 	}
+	return concorded
+}
 
-	return merged
+// anyAuthority reports whether any of the ids are looked up by authority, rather than by canonical uuid
+func anyAuthority(idsByAuthority map[string][]string) bool {
+	for authority := range idsByAuthority {
+		if authority != concepts.NoAuthority {
+			return true
+		}
+	}
+	return false
 }
 
 func conceptIdentifiersToUUIDs(identifiers map[string][]concepts.Identifier) []string {
@@ -118,6 +760,14 @@ func conceptIdentifiersToUUIDs(identifiers map[string][]concepts.Identifier) []s
 	return uuids
 }
 
+// setRetryAfter tells clients when to retry, if the request failed fast because an upstream circuit breaker is open
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var circuitErr *concepts.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(circuitErr.RetryAfter.Seconds()))))
+	}
+}
+
 func writeJSON(msg string, status int, w http.ResponseWriter) {
 	resp := make(map[string]string)
 	resp["message"] = msg
@@ -127,6 +777,22 @@ func writeJSON(msg string, status int, w http.ResponseWriter) {
 	enc.Encode(resp)
 }
 
+// getBoolParam returns the value of the single valued boolean query parameter, or the default value if it is not provided
+func getBoolParam(req *http.Request, param string, defaultValue bool) (bool, error) {
+	values, found := getMultiValuedParam(req, param)
+	if !found {
+		return defaultValue, nil
+	}
+	if len(values) != 1 {
+		return false, fmt.Errorf("Please provide one value for '%v' query parameter", param)
+	}
+	value, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, fmt.Errorf("Please provide a valid boolean for '%v' query parameter", param)
+	}
+	return value, nil
+}
+
 func getMultiValuedParam(req *http.Request, param string) ([]string, bool) {
 	query := req.URL.Query()
 	values, found := query[param]
@@ -1,15 +1,21 @@
 package resources
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Financial-Times/internal-concordances/concepts"
+	"github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var errComputerSaysNo = errors.New("computer says no")
@@ -48,11 +54,11 @@ func TestGetConcordancesFails(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=a-uuid", nil)
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
 	req.Header.Add("X-Request-Id", "tid_TestGetConcordancesFails")
 	w := httptest.NewRecorder()
 
-	concordances.On("GetConcordances", "tid_TestGetConcordancesFails", "", []string{"a-uuid"}).
+	concordances.On("GetConcordances", "tid_TestGetConcordancesFails", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
 		Return(make(map[string][]concepts.Identifier), errComputerSaysNo)
 
 	InternalConcordances(concordances, search)(w, req)
@@ -68,24 +74,24 @@ func TestGetConcordancesReturnsNoData(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=a-uuid", nil)
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
 	req.Header.Add("X-Request-Id", "tid_TestGetConcordancesReturnsNoData")
 	w := httptest.NewRecorder()
 
-	concordances.On("GetConcordances", "tid_TestGetConcordancesReturnsNoData", "", []string{"a-uuid"}).
+	concordances.On("GetConcordances", "tid_TestGetConcordancesReturnsNoData", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
 		Return(make(map[string][]concepts.Identifier), nil)
 
 	InternalConcordances(concordances, search)(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, `{"concepts":{}}`, strings.TrimSpace(w.Body.String()))
+	assert.Equal(t, `{"concepts":{},"notFound":[{"id":"58dbb3e3-fc59-5d96-b796-232283dc3a2f","reason":"noConcordance"}]}`, strings.TrimSpace(w.Body.String()))
 
 	concordances.AssertExpectations(t)
 	search.AssertExpectations(t)
 }
 
 func TestInternalConcordancesEmptyAuthorityParamSupplied(t *testing.T) {
-	req := httptest.NewRequest("GET", "/?ids=a-uuid&authority=", nil)
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&authority=", nil)
 	w := httptest.NewRecorder()
 
 	InternalConcordances(nil, nil)(w, req)
@@ -95,7 +101,7 @@ func TestInternalConcordancesEmptyAuthorityParamSupplied(t *testing.T) {
 }
 
 func TestInternalConcordancesMultipleAuthorityParamsSupplied(t *testing.T) {
-	req := httptest.NewRequest("GET", "/?ids=a-uuid&authority=au1&authority=au2", nil)
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&authority=au1&authority=au2", nil)
 	w := httptest.NewRecorder()
 
 	InternalConcordances(nil, nil)(w, req)
@@ -108,17 +114,17 @@ func TestGetConcordancesReturnsNoDataWithAuthorityRequestParameter(t *testing.T)
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=a-uuid&authority=a-valid-authority", nil)
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&authority=SMARTLOGIC", nil)
 	req.Header.Add("X-Request-Id", "tid_TestGetConcordancesReturnsNoDataWithAuthorityRequestParameter")
 	w := httptest.NewRecorder()
 
-	concordances.On("GetConcordances", "tid_TestGetConcordancesReturnsNoDataWithAuthorityRequestParameter", "a-valid-authority", []string{"a-uuid"}).
+	concordances.On("GetConcordances", "tid_TestGetConcordancesReturnsNoDataWithAuthorityRequestParameter", concepts.SmartlogicAuthority, []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
 		Return(make(map[string][]concepts.Identifier), nil)
 
 	InternalConcordances(concordances, search)(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, `{"concepts":{}}`, strings.TrimSpace(w.Body.String()))
+	assert.Equal(t, `{"concepts":{},"notFound":[{"id":"58dbb3e3-fc59-5d96-b796-232283dc3a2f","reason":"noConcordance"}]}`, strings.TrimSpace(w.Body.String()))
 
 	concordances.AssertExpectations(t)
 	search.AssertExpectations(t)
@@ -128,20 +134,20 @@ func TestSearchByIDsFails(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=a-uuid", nil)
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
 	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsFails")
 	w := httptest.NewRecorder()
 
 	identifiers := map[string][]concepts.Identifier{
-		"a-uuid": []concepts.Identifier{
-			{Authority: "authority", IdentifierValue: "a-uuid"},
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": []concepts.Identifier{
+			{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
 		},
 	}
 
-	concordances.On("GetConcordances", "tid_TestSearchByIDsFails", "", []string{"a-uuid"}).
+	concordances.On("GetConcordances", "tid_TestSearchByIDsFails", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
 		Return(identifiers, nil)
 
-	search.On("ByIDs", "tid_TestSearchByIDsFails", []string{"a-uuid"}).Return(make(map[string]concepts.Concept), errComputerSaysNo)
+	search.On("ByIDs", "tid_TestSearchByIDsFails", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(make(map[string]concepts.Concept), errComputerSaysNo)
 
 	InternalConcordances(concordances, search)(w, req)
 
@@ -156,32 +162,32 @@ func TestSearchByIDs(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=a-concorded-uuid", nil)
+	req := httptest.NewRequest("GET", "/?ids=943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", nil)
 	req.Header.Add("X-Request-Id", "tid_TestSearchByIDs")
 	w := httptest.NewRecorder()
 
 	identifiers := map[string][]concepts.Identifier{
-		"a-uuid": []concepts.Identifier{
-			{Authority: "authority", IdentifierValue: "a-concorded-uuid"},
-			{Authority: "authority", IdentifierValue: "a-uuid"},
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": []concepts.Identifier{
+			{Authority: "authority", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+			{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
 		},
 	}
 
-	concordances.On("GetConcordances", "tid_TestSearchByIDs", "", []string{"a-concorded-uuid"}).
+	concordances.On("GetConcordances", "tid_TestSearchByIDs", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
 		Return(identifiers, nil)
 
 	expectedConcepts := map[string]concepts.Concept{
-		"a-uuid": {ID: "http://www.ft.com/thing/a-uuid", PrefLabel: "Donald Trump"},
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f", PrefLabel: "Donald Trump"},
 	}
 
 	expectedResponse := internalConcordancesResponse{Concepts: map[string]concepts.Concept{
-		"a-concorded-uuid": {
-			ID:        "http://www.ft.com/thing/a-uuid",
+		"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+			ID:        "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f",
 			PrefLabel: "Donald Trump",
 		},
 	}}
 
-	search.On("ByIDs", "tid_TestSearchByIDs", []string{"a-uuid"}).
+	search.On("ByIDs", "tid_TestSearchByIDs", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
 		Return(expectedConcepts, nil)
 
 	InternalConcordances(concordances, search)(w, req)
@@ -199,31 +205,31 @@ func TestSearchByIDsOneConceptNotFound(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=found-this-one&ids=but-not-this-one", nil)
+	req := httptest.NewRequest("GET", "/?ids=136a2b54-b2ae-57a0-a0ff-79e1f0358b6c&ids=e3e7590f-1928-53ef-8ff3-99d1432fba0b", nil)
 	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsOneConceptNotFound")
 	w := httptest.NewRecorder()
 
 	identifiers := map[string][]concepts.Identifier{
-		"found-this-one": []concepts.Identifier{
-			{Authority: "authority", IdentifierValue: "found-this-one"},
+		"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c": []concepts.Identifier{
+			{Authority: "authority", IdentifierValue: "136a2b54-b2ae-57a0-a0ff-79e1f0358b6c"},
 		},
 	}
 
-	concordances.On("GetConcordances", "tid_TestSearchByIDsOneConceptNotFound", "", []string{"but-not-this-one", "found-this-one"}).
+	concordances.On("GetConcordances", "tid_TestSearchByIDsOneConceptNotFound", "", []string{"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c", "e3e7590f-1928-53ef-8ff3-99d1432fba0b"}).
 		Return(identifiers, nil)
 
 	expectedConcepts := map[string]concepts.Concept{
-		"found-this-one": {ID: "http://www.ft.com/thing/found-this-one", PrefLabel: "Donald Trump"},
+		"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c": {ID: "http://www.ft.com/thing/136a2b54-b2ae-57a0-a0ff-79e1f0358b6c", PrefLabel: "Donald Trump"},
 	}
 
 	expectedResponse := internalConcordancesResponse{Concepts: map[string]concepts.Concept{
-		"found-this-one": {
-			ID:        "http://www.ft.com/thing/found-this-one",
+		"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c": {
+			ID:        "http://www.ft.com/thing/136a2b54-b2ae-57a0-a0ff-79e1f0358b6c",
 			PrefLabel: "Donald Trump",
 		},
-	}}
+	}, NotFound: []notFound{{ID: "e3e7590f-1928-53ef-8ff3-99d1432fba0b", Reason: reasonNoConcordance}}}
 
-	search.On("ByIDs", "tid_TestSearchByIDsOneConceptNotFound", []string{"found-this-one"}).
+	search.On("ByIDs", "tid_TestSearchByIDsOneConceptNotFound", []string{"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c"}).
 		Return(expectedConcepts, nil)
 
 	InternalConcordances(concordances, search)(w, req)
@@ -241,40 +247,40 @@ func TestSearchByIDsIncludeDeprecatedNotSet(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=active-concept&ids=deprecated-concept", nil)
+	req := httptest.NewRequest("GET", "/?ids=2a7bff6d-9b63-5b96-ac3a-646bb9d00f61&ids=eee00ba1-9e86-5758-a827-1193c04362b5", nil)
 	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsIncludeDeprecated")
 	w := httptest.NewRecorder()
 
 	identifiers := map[string][]concepts.Identifier{
-		"active-concept": {
-			{Authority: "authority", IdentifierValue: "active-concept"},
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {
+			{Authority: "authority", IdentifierValue: "2a7bff6d-9b63-5b96-ac3a-646bb9d00f61"},
 		},
-		"deprecated-concept": {
-			{Authority: "authority", IdentifierValue: "deprecated-concept"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {
+			{Authority: "authority", IdentifierValue: "eee00ba1-9e86-5758-a827-1193c04362b5"},
 		},
 	}
 
-	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeDeprecated", "", []string{"active-concept", "deprecated-concept"}).
+	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeDeprecated", "", []string{"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", "eee00ba1-9e86-5758-a827-1193c04362b5"}).
 		Return(identifiers, nil)
 
 	expectedConcepts := map[string]concepts.Concept{
-		"active-concept":     {ID: "http://www.ft.com/thing/active-concept", PrefLabel: "Donald Trump"},
-		"deprecated-concept": {ID: "http://www.ft.com/thing/deprecated-concept", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {ID: "http://www.ft.com/thing/2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", PrefLabel: "Donald Trump"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {ID: "http://www.ft.com/thing/eee00ba1-9e86-5758-a827-1193c04362b5", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
 	}
 
 	expectedResponse := internalConcordancesResponse{Concepts: map[string]concepts.Concept{
-		"active-concept": {
-			ID:        "http://www.ft.com/thing/active-concept",
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {
+			ID:        "http://www.ft.com/thing/2a7bff6d-9b63-5b96-ac3a-646bb9d00f61",
 			PrefLabel: "Donald Trump",
 		},
-		"deprecated-concept": {
-			ID:           "http://www.ft.com/thing/deprecated-concept",
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {
+			ID:           "http://www.ft.com/thing/eee00ba1-9e86-5758-a827-1193c04362b5",
 			PrefLabel:    "NOT Donald Trump",
 			IsDeprecated: true,
 		},
 	}}
 
-	search.On("ByIDs", "tid_TestSearchByIDsIncludeDeprecated", []string{"active-concept", "deprecated-concept"}).
+	search.On("ByIDs", "tid_TestSearchByIDsIncludeDeprecated", []string{"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", "eee00ba1-9e86-5758-a827-1193c04362b5"}).
 		Return(expectedConcepts, nil)
 
 	InternalConcordances(concordances, search)(w, req)
@@ -292,35 +298,35 @@ func TestSearchByIDsIncludeDeprecatedSetFalse(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=active-concept&ids=deprecated-concept&include_deprecated=false", nil)
+	req := httptest.NewRequest("GET", "/?ids=2a7bff6d-9b63-5b96-ac3a-646bb9d00f61&ids=eee00ba1-9e86-5758-a827-1193c04362b5&include_deprecated=false", nil)
 	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsIncludeDeprecated")
 	w := httptest.NewRecorder()
 
 	identifiers := map[string][]concepts.Identifier{
-		"active-concept": {
-			{Authority: "authority", IdentifierValue: "active-concept"},
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {
+			{Authority: "authority", IdentifierValue: "2a7bff6d-9b63-5b96-ac3a-646bb9d00f61"},
 		},
-		"deprecated-concept": {
-			{Authority: "authority", IdentifierValue: "deprecated-concept"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {
+			{Authority: "authority", IdentifierValue: "eee00ba1-9e86-5758-a827-1193c04362b5"},
 		},
 	}
 
-	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeDeprecated", "", []string{"active-concept", "deprecated-concept"}).
+	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeDeprecated", "", []string{"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", "eee00ba1-9e86-5758-a827-1193c04362b5"}).
 		Return(identifiers, nil)
 
 	expectedConcepts := map[string]concepts.Concept{
-		"active-concept":     {ID: "http://www.ft.com/thing/active-concept", PrefLabel: "Donald Trump"},
-		"deprecated-concept": {ID: "http://www.ft.com/thing/deprecated-concept", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {ID: "http://www.ft.com/thing/2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", PrefLabel: "Donald Trump"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {ID: "http://www.ft.com/thing/eee00ba1-9e86-5758-a827-1193c04362b5", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
 	}
 
 	expectedResponse := internalConcordancesResponse{Concepts: map[string]concepts.Concept{
-		"active-concept": {
-			ID:        "http://www.ft.com/thing/active-concept",
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {
+			ID:        "http://www.ft.com/thing/2a7bff6d-9b63-5b96-ac3a-646bb9d00f61",
 			PrefLabel: "Donald Trump",
 		},
-	}}
+	}, NotFound: []notFound{{ID: "eee00ba1-9e86-5758-a827-1193c04362b5", Reason: reasonDeprecated}}}
 
-	search.On("ByIDs", "tid_TestSearchByIDsIncludeDeprecated", []string{"active-concept", "deprecated-concept"}).
+	search.On("ByIDs", "tid_TestSearchByIDsIncludeDeprecated", []string{"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", "eee00ba1-9e86-5758-a827-1193c04362b5"}).
 		Return(expectedConcepts, nil)
 
 	InternalConcordances(concordances, search)(w, req)
@@ -338,40 +344,40 @@ func TestSearchByIDsIncludeDeprecatedSetTrue(t *testing.T) {
 	concordances := new(mockConcordances)
 	search := new(mockSearch)
 
-	req := httptest.NewRequest("GET", "/?ids=active-concept&ids=deprecated-concept&include_deprecated=true", nil)
+	req := httptest.NewRequest("GET", "/?ids=2a7bff6d-9b63-5b96-ac3a-646bb9d00f61&ids=eee00ba1-9e86-5758-a827-1193c04362b5&include_deprecated=true", nil)
 	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsIncludeDeprecatedSet")
 	w := httptest.NewRecorder()
 
 	identifiers := map[string][]concepts.Identifier{
-		"active-concept": {
-			{Authority: "authority", IdentifierValue: "active-concept"},
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {
+			{Authority: "authority", IdentifierValue: "2a7bff6d-9b63-5b96-ac3a-646bb9d00f61"},
 		},
-		"deprecated-concept": {
-			{Authority: "authority", IdentifierValue: "deprecated-concept"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {
+			{Authority: "authority", IdentifierValue: "eee00ba1-9e86-5758-a827-1193c04362b5"},
 		},
 	}
 
-	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeDeprecatedSet", "", []string{"active-concept", "deprecated-concept"}).
+	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeDeprecatedSet", "", []string{"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", "eee00ba1-9e86-5758-a827-1193c04362b5"}).
 		Return(identifiers, nil)
 
 	expectedConcepts := map[string]concepts.Concept{
-		"active-concept":     {ID: "http://www.ft.com/thing/active-concept", PrefLabel: "Donald Trump"},
-		"deprecated-concept": {ID: "http://www.ft.com/thing/deprecated-concept", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {ID: "http://www.ft.com/thing/2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", PrefLabel: "Donald Trump"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {ID: "http://www.ft.com/thing/eee00ba1-9e86-5758-a827-1193c04362b5", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
 	}
 
 	expectedResponse := internalConcordancesResponse{Concepts: map[string]concepts.Concept{
-		"active-concept": {
-			ID:        "http://www.ft.com/thing/active-concept",
+		"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61": {
+			ID:        "http://www.ft.com/thing/2a7bff6d-9b63-5b96-ac3a-646bb9d00f61",
 			PrefLabel: "Donald Trump",
 		},
-		"deprecated-concept": {
-			ID:           "http://www.ft.com/thing/deprecated-concept",
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {
+			ID:           "http://www.ft.com/thing/eee00ba1-9e86-5758-a827-1193c04362b5",
 			PrefLabel:    "NOT Donald Trump",
 			IsDeprecated: true,
 		},
 	}}
 
-	search.On("ByIDs", "tid_TestSearchByIDsIncludeDeprecatedSet", []string{"active-concept", "deprecated-concept"}).
+	search.On("ByIDs", "tid_TestSearchByIDsIncludeDeprecatedSet", []string{"2a7bff6d-9b63-5b96-ac3a-646bb9d00f61", "eee00ba1-9e86-5758-a827-1193c04362b5"}).
 		Return(expectedConcepts, nil)
 
 	InternalConcordances(concordances, search)(w, req)
@@ -385,8 +391,58 @@ func TestSearchByIDsIncludeDeprecatedSetTrue(t *testing.T) {
 	search.AssertExpectations(t)
 }
 
+func TestSearchByIDsReportsNotFound(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=8423e9d4-bb0a-5fc2-ba47-3b723765fb65&ids=5a55a9ba-0105-5ca0-a98f-dce112208e76&ids=4426e799-000f-5d45-92ec-82e39765236a&ids=2bc85599-0ad6-54f4-91d4-3e4d16abb9b0&ids=5a55a9ba-0105-5ca0-a98f-dce112208e76&include_deprecated=false", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsReportsNotFound")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"b8887e73-7c19-549d-8fb1-dfa2905bb4f3": {
+			{Authority: "authority", IdentifierValue: "8423e9d4-bb0a-5fc2-ba47-3b723765fb65"},
+		},
+		"4c76cd6f-98f3-5f87-a356-3080dbf5b81b": {
+			{Authority: "authority", IdentifierValue: "4426e799-000f-5d45-92ec-82e39765236a"},
+		},
+		"dc328bf7-4d52-579c-bc35-e9484d9b4ebe": {
+			{Authority: "authority", IdentifierValue: "2bc85599-0ad6-54f4-91d4-3e4d16abb9b0"},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsReportsNotFound", "", []string{"2bc85599-0ad6-54f4-91d4-3e4d16abb9b0", "4426e799-000f-5d45-92ec-82e39765236a", "5a55a9ba-0105-5ca0-a98f-dce112208e76", "5a55a9ba-0105-5ca0-a98f-dce112208e76", "8423e9d4-bb0a-5fc2-ba47-3b723765fb65"}).
+		Return(identifiers, nil)
+
+	search.On("ByIDs", "tid_TestSearchByIDsReportsNotFound", []string{"4c76cd6f-98f3-5f87-a356-3080dbf5b81b", "b8887e73-7c19-549d-8fb1-dfa2905bb4f3", "dc328bf7-4d52-579c-bc35-e9484d9b4ebe"}).
+		Return(map[string]concepts.Concept{
+			"b8887e73-7c19-549d-8fb1-dfa2905bb4f3": {ID: "http://www.ft.com/thing/b8887e73-7c19-549d-8fb1-dfa2905bb4f3", PrefLabel: "Donald Trump"},
+			"dc328bf7-4d52-579c-bc35-e9484d9b4ebe": {ID: "http://www.ft.com/thing/dc328bf7-4d52-579c-bc35-e9484d9b4ebe", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
+		}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"8423e9d4-bb0a-5fc2-ba47-3b723765fb65": {ID: "http://www.ft.com/thing/b8887e73-7c19-549d-8fb1-dfa2905bb4f3", PrefLabel: "Donald Trump"},
+		},
+		NotFound: []notFound{
+			{ID: "5a55a9ba-0105-5ca0-a98f-dce112208e76", Reason: reasonNoConcordance},
+			{ID: "4426e799-000f-5d45-92ec-82e39765236a", Reason: reasonMissingFromSearch},
+			{ID: "2bc85599-0ad6-54f4-91d4-3e4d16abb9b0", Reason: reasonDeprecated},
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
 func TestInternalConcordancesMultipleIncludeDeprecatedParamsSupplied(t *testing.T) {
-	req := httptest.NewRequest("GET", "/?ids=active-concept&ids=deprecated-concept&include_deprecated=true&include_deprecated=false", nil)
+	req := httptest.NewRequest("GET", "/?ids=2a7bff6d-9b63-5b96-ac3a-646bb9d00f61&ids=eee00ba1-9e86-5758-a827-1193c04362b5&include_deprecated=true&include_deprecated=false", nil)
 	w := httptest.NewRecorder()
 
 	InternalConcordances(nil, nil)(w, req)
@@ -396,7 +452,7 @@ func TestInternalConcordancesMultipleIncludeDeprecatedParamsSupplied(t *testing.
 }
 
 func TestInternalConcordancesInvalidIncludeDeprecatedParamsSupplied(t *testing.T) {
-	req := httptest.NewRequest("GET", "/?ids=active-concept&ids=deprecated-concept&include_deprecated=whynot", nil)
+	req := httptest.NewRequest("GET", "/?ids=2a7bff6d-9b63-5b96-ac3a-646bb9d00f61&ids=eee00ba1-9e86-5758-a827-1193c04362b5&include_deprecated=whynot", nil)
 	w := httptest.NewRecorder()
 
 	InternalConcordances(nil, nil)(w, req)
@@ -404,3 +460,1181 @@ func TestInternalConcordancesInvalidIncludeDeprecatedParamsSupplied(t *testing.T
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Equal(t, `{"message":"Please provide a valid boolean for 'include_deprecated' query parameter"}`, strings.TrimSpace(w.Body.String()))
 }
+
+func TestSearchByIDsStaleConcepts(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsStaleConcepts")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {
+			{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsStaleConcepts", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(identifiers, nil)
+
+	search.On("ByIDs", "tid_TestSearchByIDsStaleConcepts", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string]concepts.Concept{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f", PrefLabel: "Donald Trump", Stale: true}}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `110 - "Response is Stale"`, w.Header().Get("Warning"))
+	assert.Equal(t, `{"concepts":{"58dbb3e3-fc59-5d96-b796-232283dc3a2f":{"id":"http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f","prefLabel":"Donald Trump"}}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestUpstreamCallsShareRequestBudget(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestUpstreamCallsShareRequestBudget")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {
+			{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestUpstreamCallsShareRequestBudget", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(identifiers, nil)
+	search.On("ByIDs", "tid_TestUpstreamCallsShareRequestBudget", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(map[string]concepts.Concept{}, nil)
+
+	start := time.Now()
+	WithRequestBudget(10*time.Second, InternalConcordances(concordances, search))(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	concordancesDeadline, ok := concordances.ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, start.Add(5*time.Second), concordancesDeadline, time.Second)
+
+	searchDeadline, ok := search.ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, start.Add(10*time.Second), searchDeadline, time.Second)
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestUpstreamCallsWithoutRequestBudget(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestUpstreamCallsWithoutRequestBudget")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestUpstreamCallsWithoutRequestBudget", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(make(map[string][]concepts.Identifier), nil)
+
+	WithRequestBudget(0, InternalConcordances(concordances, nil))(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, ok := concordances.ctx.Deadline()
+	assert.False(t, ok)
+
+	concordances.AssertExpectations(t)
+}
+
+func TestGetConcordancesCircuitOpen(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestGetConcordancesCircuitOpen")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestGetConcordancesCircuitOpen", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(make(map[string][]concepts.Identifier), &concepts.CircuitOpenError{Upstream: "public-concordances-api", RetryAfter: 2500 * time.Millisecond})
+
+	InternalConcordances(concordances, nil)(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "3", w.Header().Get("Retry-After"))
+	assert.Equal(t, `{"message":"Public Concordances request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+}
+
+func TestSearchByIDsCircuitOpen(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsCircuitOpen")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {
+			{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsCircuitOpen", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(identifiers, nil)
+	search.On("ByIDs", "tid_TestSearchByIDsCircuitOpen", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(make(map[string]concepts.Concept), &concepts.CircuitOpenError{Upstream: "concept-search-api", RetryAfter: 30 * time.Second})
+
+	InternalConcordances(concordances, search)(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, `{"message":"Concept Search request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestSearchByIDsCircuitOpenBehindCache(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&ids=5d0fedcd-20e5-48d7-953e-b8e72865828c", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsCircuitOpenBehindCache")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}},
+		"5d0fedcd-20e5-48d7-953e-b8e72865828c": {{Authority: "authority", IdentifierValue: "5d0fedcd-20e5-48d7-953e-b8e72865828c"}},
+	}
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsCircuitOpenBehindCache", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f", "5d0fedcd-20e5-48d7-953e-b8e72865828c"}).Return(identifiers, nil)
+	search.On("ByIDs", "tid_TestSearchByIDsCircuitOpenBehindCache", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string]concepts.Concept{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f"}}, nil)
+	search.On("ByIDs", "tid_TestSearchByIDsCircuitOpenBehindCache", []string{"5d0fedcd-20e5-48d7-953e-b8e72865828c"}).
+		Return(make(map[string]concepts.Concept), &concepts.CircuitOpenError{Upstream: "concept-search-api", RetryAfter: 30 * time.Second})
+
+	cachedSearch := concepts.NewCachedSearch(search, time.Minute, time.Minute, 0, 10)
+	_, err := cachedSearch.ByIDs(context.Background(), "tid_TestSearchByIDsCircuitOpenBehindCache", "58dbb3e3-fc59-5d96-b796-232283dc3a2f")
+	require.NoError(t, err)
+
+	InternalConcordances(concordances, cachedSearch)(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, `{"message":"Concept Search request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestPartialGetConcordancesFails(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&partial=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestPartialGetConcordancesFails")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestPartialGetConcordancesFails", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier(nil), errComputerSaysNo)
+
+	InternalConcordances(concordances, nil)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{},"errors":{"58dbb3e3-fc59-5d96-b796-232283dc3a2f":"Public Concordances request failed: computer says no"}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+}
+
+func TestPartialResponse(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=8423e9d4-bb0a-5fc2-ba47-3b723765fb65&ids=c2a377d9-c251-5833-8ceb-b72bb65ca129&ids=10317dce-2cb6-5a23-be9a-634ff626ead4&partial=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestPartialResponse")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"b8887e73-7c19-549d-8fb1-dfa2905bb4f3": {
+			{Authority: "authority", IdentifierValue: "8423e9d4-bb0a-5fc2-ba47-3b723765fb65"},
+		},
+		"7927b4c2-464b-52ba-87e3-db2fdebc0573": {
+			{Authority: "authority", IdentifierValue: "c2a377d9-c251-5833-8ceb-b72bb65ca129"},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestPartialResponse", "", []string{"10317dce-2cb6-5a23-be9a-634ff626ead4", "8423e9d4-bb0a-5fc2-ba47-3b723765fb65", "c2a377d9-c251-5833-8ceb-b72bb65ca129"}).
+		Return(identifiers, &concepts.PartialError{Failed: map[string]error{"10317dce-2cb6-5a23-be9a-634ff626ead4": errComputerSaysNo}})
+
+	search.On("ByIDs", "tid_TestPartialResponse", []string{"7927b4c2-464b-52ba-87e3-db2fdebc0573", "b8887e73-7c19-549d-8fb1-dfa2905bb4f3"}).
+		Return(map[string]concepts.Concept{"b8887e73-7c19-549d-8fb1-dfa2905bb4f3": {ID: "http://www.ft.com/thing/b8887e73-7c19-549d-8fb1-dfa2905bb4f3", PrefLabel: "Donald Trump"}},
+			&concepts.PartialError{Failed: map[string]error{"7927b4c2-464b-52ba-87e3-db2fdebc0573": errComputerSaysNo}})
+
+	InternalConcordances(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"8423e9d4-bb0a-5fc2-ba47-3b723765fb65": {ID: "http://www.ft.com/thing/b8887e73-7c19-549d-8fb1-dfa2905bb4f3", PrefLabel: "Donald Trump"},
+		},
+		Errors: map[string]string{
+			"10317dce-2cb6-5a23-be9a-634ff626ead4": "Public Concordances request failed: computer says no",
+			"c2a377d9-c251-5833-8ceb-b72bb65ca129": "Concept Search request failed: computer says no",
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestPartialSearchByIDsFails(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&partial=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestPartialSearchByIDsFails")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {
+			{Authority: "authority", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestPartialSearchByIDsFails", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(identifiers, nil)
+	search.On("ByIDs", "tid_TestPartialSearchByIDsFails", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(map[string]concepts.Concept(nil), errComputerSaysNo)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{},"errors":{"58dbb3e3-fc59-5d96-b796-232283dc3a2f":"Concept Search request failed: computer says no"}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestPartialFailureWithoutPartialParam(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&ids=9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e", nil)
+	req.Header.Add("X-Request-Id", "tid_TestPartialFailureWithoutPartialParam")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestPartialFailureWithoutPartialParam", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f", "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"}).
+		Return(map[string][]concepts.Identifier{}, &concepts.PartialError{Failed: map[string]error{"58dbb3e3-fc59-5d96-b796-232283dc3a2f": errComputerSaysNo}})
+
+	InternalConcordances(concordances, nil)(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, `{"message":"Public Concordances request failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+}
+
+func TestInternalConcordancesInvalidPartialParamSupplied(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&partial=maybe", nil)
+	w := httptest.NewRecorder()
+
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide a valid boolean for 'partial' query parameter"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestSearchByIDsIncludeIdentifiers(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&include_identifiers=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsIncludeIdentifiers")
+	w := httptest.NewRecorder()
+
+	identifiers := map[string][]concepts.Identifier{
+		"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+			{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"},
+			{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="},
+		},
+	}
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeIdentifiers", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).Return(identifiers, nil)
+	search.On("ByIDs", "tid_TestSearchByIDsIncludeIdentifiers", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{"58dbb3e3-fc59-5d96-b796-232283dc3a2f":{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump","identifiers":[`+
+		`{"identifierValue":"58dbb3e3-fc59-5d96-b796-232283dc3a2f","authority":"http://api.ft.com/system/UPP"},`+
+		`{"identifierValue":"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=","authority":"http://api.ft.com/system/FT-TME"}]}}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestSearchByIDsIncludeIdentifiersWithAuthority(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=&authority=http://api.ft.com/system/FT-TME&include_identifiers=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsIncludeIdentifiersWithAuthority")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeIdentifiersWithAuthority", "http://api.ft.com/system/FT-TME", []string{"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestSearchByIDsIncludeIdentifiersWithAuthority", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+				{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+				{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="},
+			},
+		}, nil)
+	search.On("ByIDs", "tid_TestSearchByIDsIncludeIdentifiersWithAuthority", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=":{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump","identifiers":[`+
+		`{"identifierValue":"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","authority":"http://api.ft.com/system/UPP"},`+
+		`{"identifierValue":"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=","authority":"http://api.ft.com/system/FT-TME"}]}}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestPartialIncludeIdentifiersWithAuthorityFails(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("GET", "/?ids=TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=&authority=http://api.ft.com/system/FT-TME&include_identifiers=true&partial=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestPartialIncludeIdentifiersWithAuthorityFails")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestPartialIncludeIdentifiersWithAuthorityFails", "http://api.ft.com/system/FT-TME", []string{"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestPartialIncludeIdentifiersWithAuthorityFails", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string][]concepts.Identifier(nil), errComputerSaysNo)
+
+	InternalConcordances(concordances, nil)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{},"errors":{"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=":"Public Concordances request failed: computer says no"}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+}
+
+func TestInternalConcordancesInvalidIncludeIdentifiersParamSupplied(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&include_identifiers=maybe", nil)
+	w := httptest.NewRecorder()
+
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide a valid boolean for 'include_identifiers' query parameter"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestBatchSearchByIDs(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	body := `{"ids":["TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=","MTQ4-U2VjdGlvbnM=","OTk5OQ==-UE4="],"authority":"http://api.ft.com/system/FT-TME","include_deprecated":false}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_TestBatchSearchByIDs")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestBatchSearchByIDs", "http://api.ft.com/system/FT-TME", []string{"MTQ4-U2VjdGlvbnM=", "OTk5OQ==-UE4=", "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}},
+			"2b9c05fb-0c08-518e-8768-0c675f4d01b9": {{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "MTQ4-U2VjdGlvbnM="}},
+		}, nil)
+	search.On("ByIDs", "tid_TestBatchSearchByIDs", []string{"2b9c05fb-0c08-518e-8768-0c675f4d01b9", "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"},
+			"2b9c05fb-0c08-518e-8768-0c675f4d01b9": {ID: "http://www.ft.com/thing/2b9c05fb-0c08-518e-8768-0c675f4d01b9", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
+		}, nil)
+
+	InternalConcordancesBatch(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"},
+		},
+		NotFound: []notFound{
+			{ID: "MTQ4-U2VjdGlvbnM=", Reason: reasonDeprecated},
+			{ID: "OTk5OQ==-UE4=", Reason: reasonNoConcordance},
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestBatchNoIDsSupplied(t *testing.T) {
+	for _, body := range []string{`{}`, `{"ids":[]}`} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		InternalConcordancesBatch(nil, nil)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, `{"message":"Please provide ids to concord, using the 'ids' field"}`, strings.TrimSpace(w.Body.String()))
+	}
+}
+
+func TestBatchEmptyIDsSupplied(t *testing.T) {
+	concordances := new(mockConcordances)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"ids":[""]}`))
+	req.Header.Add("X-Request-Id", "tid_TestBatchEmptyIDsSupplied")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestBatchEmptyIDsSupplied", "", []string{""}).
+		Return(map[string][]concepts.Identifier(nil), concepts.ErrConceptIDsAreEmpty)
+
+	InternalConcordancesBatch(concordances, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide non-empty ids to concord, using the 'ids' field"}`, strings.TrimSpace(w.Body.String()))
+
+	concordances.AssertExpectations(t)
+}
+
+func TestBatchInvalidBody(t *testing.T) {
+	for _, body := range []string{`not json`, `{"ids":"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}`, `{"ids":["58dbb3e3-fc59-5d96-b796-232283dc3a2f"],"unknown":true}`} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		InternalConcordancesBatch(nil, nil)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), "Please provide a valid JSON body", body)
+	}
+}
+
+func TestBatchTooManyIDs(t *testing.T) {
+	body := `{"ids":["a"` + strings.Repeat(`,"a"`, maxBatchIDs-1) + `],"identifiers":[{"authority":"tme","identifierValue":"b"}]}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	InternalConcordancesBatch(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide at most 5000 ids and identifiers to concord"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestBatchBodyTooLarge(t *testing.T) {
+	body := `{"ids":["` + strings.Repeat("a", maxBatchBodyBytes) + `"]}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	InternalConcordancesBatch(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "Please provide a JSON body of at most 1048576 bytes")
+}
+
+func TestSearchByIDsMixedAuthorities(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&identifiers=http://api.ft.com/system/FT-TME:TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=&identifiers=http://api.ft.com/system/SMARTLOGIC:f0ebe9d2-71b4-5596-ba89-b62f77d60246&identifiers=http://api.ft.com/system/FACTSET:000C7F-E&partial=true", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByIDsMixedAuthorities")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestSearchByIDsMixedAuthorities", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{
+			"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestSearchByIDsMixedAuthorities", "http://api.ft.com/system/FT-TME", []string{"TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestSearchByIDsMixedAuthorities", "http://api.ft.com/system/SMARTLOGIC", []string{"f0ebe9d2-71b4-5596-ba89-b62f77d60246"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/SMARTLOGIC", IdentifierValue: "f0ebe9d2-71b4-5596-ba89-b62f77d60246"}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestSearchByIDsMixedAuthorities", "http://api.ft.com/system/FACTSET", []string{"000C7F-E"}).
+		Return(map[string][]concepts.Identifier(nil), errComputerSaysNo)
+
+	search.On("ByIDs", "tid_TestSearchByIDsMixedAuthorities", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f", "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{
+			"58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f", PrefLabel: "Donald Trump"},
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Theresa May"},
+		}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"58dbb3e3-fc59-5d96-b796-232283dc3a2f":                                      {ID: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f", PrefLabel: "Donald Trump"},
+			"http://api.ft.com/system/FT-TME:TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Theresa May"},
+			"http://api.ft.com/system/SMARTLOGIC:f0ebe9d2-71b4-5596-ba89-b62f77d60246":  {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Theresa May"},
+		},
+		Errors: map[string]string{
+			"http://api.ft.com/system/FACTSET:000C7F-E": "Public Concordances request failed: computer says no",
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestMixedAuthoritiesMatchTheRequestedAuthority(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	body := `{"identifiers":[{"authority":"http://api.ft.com/system/SMARTLOGIC","identifierValue":"58dbb3e3-fc59-5d96-b796-232283dc3a2f"},{"authority":"http://api.ft.com/system/UPP","identifierValue":"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}]}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_TestMixedAuthoritiesMatchTheRequestedAuthority")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestMixedAuthoritiesMatchTheRequestedAuthority", "http://api.ft.com/system/SMARTLOGIC", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{
+			"78846cc6-5cfd-5ccb-aa55-0272107fbe79": {{Authority: "http://api.ft.com/system/SMARTLOGIC", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestMixedAuthoritiesMatchTheRequestedAuthority", "http://api.ft.com/system/UPP", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f"}).
+		Return(map[string][]concepts.Identifier{}, nil)
+
+	search.On("ByIDs", "tid_TestMixedAuthoritiesMatchTheRequestedAuthority", []string{"78846cc6-5cfd-5ccb-aa55-0272107fbe79"}).
+		Return(map[string]concepts.Concept{"78846cc6-5cfd-5ccb-aa55-0272107fbe79": {ID: "http://www.ft.com/thing/78846cc6-5cfd-5ccb-aa55-0272107fbe79", PrefLabel: "Donald Trump"}}, nil)
+
+	InternalConcordancesBatch(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"http://api.ft.com/system/SMARTLOGIC:58dbb3e3-fc59-5d96-b796-232283dc3a2f": {ID: "http://www.ft.com/thing/78846cc6-5cfd-5ccb-aa55-0272107fbe79", PrefLabel: "Donald Trump"},
+		},
+		NotFound: []notFound{{ID: "http://api.ft.com/system/UPP:58dbb3e3-fc59-5d96-b796-232283dc3a2f", Reason: reasonNoConcordance}},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordancesInvalidIdentifiersParamSupplied(t *testing.T) {
+	for _, identifier := range []string{"an-id", "http://api.ft.com/system/FT-TME", ":an-id", "http://api.ft.com/system/FT-TME:"} {
+		req := httptest.NewRequest("GET", "/?identifiers="+identifier, nil)
+		w := httptest.NewRecorder()
+
+		InternalConcordances(nil, nil)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, identifier)
+		assert.Equal(t, `{"message":"Please provide each 'identifiers' query parameter as authority:id, not '`+identifier+`'"}`, strings.TrimSpace(w.Body.String()))
+	}
+}
+
+func TestBatchInvalidIdentifiersSupplied(t *testing.T) {
+	for _, body := range []string{`{"identifiers":[{"identifierValue":"an-id"}]}`, `{"identifiers":[{"authority":"http://api.ft.com/system/FT-TME"}]}`} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		InternalConcordancesBatch(nil, nil)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, `{"message":"Please provide a non-empty authority and identifierValue for each of the 'identifiers'"}`, strings.TrimSpace(w.Body.String()))
+	}
+}
+
+func TestSplitAuthorityID(t *testing.T) {
+	identifier, ok := splitAuthorityID("http://api.ft.com/system/FT-TME:TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=")
+	assert.True(t, ok)
+	assert.Equal(t, concepts.Identifier{Authority: "http://api.ft.com/system/FT-TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="}, identifier)
+
+	identifier, ok = splitAuthorityID("TME:http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f")
+	assert.True(t, ok)
+	assert.Equal(t, concepts.Identifier{Authority: "TME", IdentifierValue: "http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f"}, identifier)
+}
+
+func TestSearchByThingURIs(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f&ids=http://api.ft.com/things/58dbb3e3-fc59-5d96-b796-232283dc3a2f&ids=58dbb3e3-fc59-5d96-b796-232283dc3a2f&ids=http://www.ft.com/thing/ea1f788c-a4ff-5bfb-bf75-28dbe949ba70", nil)
+	req.Header.Add("X-Request-Id", "tid_TestSearchByThingURIs")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestSearchByThingURIs", "", []string{"58dbb3e3-fc59-5d96-b796-232283dc3a2f", "58dbb3e3-fc59-5d96-b796-232283dc3a2f", "58dbb3e3-fc59-5d96-b796-232283dc3a2f", "ea1f788c-a4ff-5bfb-bf75-28dbe949ba70"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "58dbb3e3-fc59-5d96-b796-232283dc3a2f"}},
+		}, nil)
+	search.On("ByIDs", "tid_TestSearchByThingURIs", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	concept := concepts.Concept{ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"}
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"http://www.ft.com/thing/58dbb3e3-fc59-5d96-b796-232283dc3a2f":  concept,
+			"http://api.ft.com/things/58dbb3e3-fc59-5d96-b796-232283dc3a2f": concept,
+			"58dbb3e3-fc59-5d96-b796-232283dc3a2f":                          concept,
+		},
+		NotFound: []notFound{{ID: "http://www.ft.com/thing/ea1f788c-a4ff-5bfb-bf75-28dbe949ba70", Reason: reasonNoConcordance}},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordancesInvalidIDsSupplied(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?ids=1f2c7277-5f74-3397-b852-92bcb1096021&ids=1f2c7277-5f74-3397-b852&ids=http://www.ft.com/thing/not-a-uuid&identifiers=http://api.ft.com/system/FT-TME:not-a-tme-id", nil)
+	w := httptest.NewRecorder()
+
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide ids in the format of their authority","invalidIds":["1f2c7277-5f74-3397-b852","http://www.ft.com/thing/not-a-uuid","http://api.ft.com/system/FT-TME:not-a-tme-id"]}`, w.Body.String())
+}
+
+func TestLenientInvalidIDsAreNotFound(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	body := `{"ids":["not-a-uuid","1f2c7277-5f74-3397-b852-92bcb1096021"],"identifiers":[{"authority":"http://api.ft.com/system/FACTSET","identifierValue":"not-a-factset-id"}],"lenient":true}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Add("X-Request-Id", "tid_TestLenientInvalidIDsAreNotFound")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestLenientInvalidIDsAreNotFound", "", []string{"1f2c7277-5f74-3397-b852-92bcb1096021"}).
+		Return(map[string][]concepts.Identifier{
+			"1f2c7277-5f74-3397-b852-92bcb1096021": {{Authority: "http://api.ft.com/system/UPP", IdentifierValue: "1f2c7277-5f74-3397-b852-92bcb1096021"}},
+		}, nil)
+	search.On("ByIDs", "tid_TestLenientInvalidIDsAreNotFound", []string{"1f2c7277-5f74-3397-b852-92bcb1096021"}).
+		Return(map[string]concepts.Concept{"1f2c7277-5f74-3397-b852-92bcb1096021": {ID: "http://www.ft.com/thing/1f2c7277-5f74-3397-b852-92bcb1096021", PrefLabel: "Donald Trump"}}, nil)
+
+	InternalConcordancesBatch(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"1f2c7277-5f74-3397-b852-92bcb1096021": {ID: "http://www.ft.com/thing/1f2c7277-5f74-3397-b852-92bcb1096021", PrefLabel: "Donald Trump"},
+		},
+		NotFound: []notFound{
+			{ID: "not-a-uuid", Reason: reasonInvalidID},
+			{ID: "http://api.ft.com/system/FACTSET:not-a-factset-id", Reason: reasonInvalidID},
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestLenientOnlyInvalidIDs(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?ids=not-a-uuid&lenient=true", nil)
+	w := httptest.NewRecorder()
+
+	InternalConcordances(new(mockConcordances), nil)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{},"notFound":[{"id":"not-a-uuid","reason":"invalidId"}]}`, w.Body.String())
+}
+
+func TestInternalConcordancesUnsupportedAuthority(t *testing.T) {
+	message := `{"message":"Please provide a supported authority instead of 'http://api.ft.com/system/UNKNOWN', one of ` +
+		`UPP (http://api.ft.com/system/UPP), TME (http://api.ft.com/system/FT-TME), SMARTLOGIC (http://api.ft.com/system/SMARTLOGIC), FACTSET (http://api.ft.com/system/FACTSET), ` +
+		`LEI (http://api.ft.com/system/LEI), MANAGEDLOCATION (http://api.ft.com/system/MANAGEDLOCATION), ISO-3166-1 (http://api.ft.com/system/ISO-3166-1), ` +
+		`GEONAMES (http://api.ft.com/system/GEONAMES), WIKIDATA (http://api.ft.com/system/WIKIDATA), DBPEDIA (http://api.ft.com/system/DBPEDIA), NAICS (http://api.ft.com/system/NAICS)"}`
+
+	req := httptest.NewRequest("GET", "/?ids=anything&authority=http://api.ft.com/system/UNKNOWN", nil)
+	w := httptest.NewRecorder()
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, message, strings.TrimSpace(w.Body.String()))
+
+	req = httptest.NewRequest("GET", "/?identifiers=http://api.ft.com/system/UNKNOWN:anything", nil)
+	w = httptest.NewRecorder()
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, message, strings.TrimSpace(w.Body.String()))
+
+	for _, body := range []string{
+		`{"ids":["anything"],"authority":"http://api.ft.com/system/UNKNOWN"}`,
+		`{"identifiers":[{"authority":"http://api.ft.com/system/UNKNOWN","identifierValue":"anything"}]}`,
+	} {
+		req = httptest.NewRequest("POST", "/", strings.NewReader(body))
+		w = httptest.NewRecorder()
+		InternalConcordancesBatch(nil, nil)(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, message, strings.TrimSpace(w.Body.String()), body)
+	}
+}
+
+func TestAuthorityAliases(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=MTQ4-U2VjdGlvbnM=&authority=tme&identifiers=FACTSET:000C7F-E", nil)
+	req.Header.Add("X-Request-Id", "tid_TestAuthorityAliases")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestAuthorityAliases", concepts.TMEAuthority, []string{"MTQ4-U2VjdGlvbnM="}).
+		Return(map[string][]concepts.Identifier{
+			"1f2c7277-5f74-3397-b852-92bcb1096021": {{Authority: concepts.TMEAuthority, IdentifierValue: "MTQ4-U2VjdGlvbnM="}},
+		}, nil)
+	concordances.On("GetConcordances", "tid_TestAuthorityAliases", concepts.FactsetAuthority, []string{"000C7F-E"}).
+		Return(map[string][]concepts.Identifier{
+			"5d0fedcd-20e5-48d7-953e-b8e72865828c": {{Authority: concepts.FactsetAuthority, IdentifierValue: "000C7F-E"}},
+		}, nil)
+	search.On("ByIDs", "tid_TestAuthorityAliases", []string{"1f2c7277-5f74-3397-b852-92bcb1096021", "5d0fedcd-20e5-48d7-953e-b8e72865828c"}).
+		Return(map[string]concepts.Concept{
+			"1f2c7277-5f74-3397-b852-92bcb1096021": {ID: "http://www.ft.com/thing/1f2c7277-5f74-3397-b852-92bcb1096021", PrefLabel: "Companies"},
+			"5d0fedcd-20e5-48d7-953e-b8e72865828c": {ID: "http://www.ft.com/thing/5d0fedcd-20e5-48d7-953e-b8e72865828c", PrefLabel: "Apple Inc"},
+		}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"MTQ4-U2VjdGlvbnM=": {ID: "http://www.ft.com/thing/1f2c7277-5f74-3397-b852-92bcb1096021", PrefLabel: "Companies"},
+			"FACTSET:000C7F-E":  {ID: "http://www.ft.com/thing/5d0fedcd-20e5-48d7-953e-b8e72865828c", PrefLabel: "Apple Inc"},
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestSearchByIDsSelectsFields(t *testing.T) {
+	richConcept := concepts.Concept{
+		ID:           "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9",
+		PrefLabel:    "Donald Trump",
+		Aliases:      []string{"Trump"},
+		ScopeNote:    "45th President of the United States",
+		DirectType:   "http://www.ft.com/ontology/person/Person",
+		Types:        []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/person/Person"},
+		EmailAddress: "donald@example.com",
+	}
+
+	for query, expected := range map[string]concepts.Concept{
+		"":                                  {ID: richConcept.ID, PrefLabel: richConcept.PrefLabel},
+		"&fields=aliases":                   {ID: richConcept.ID, PrefLabel: richConcept.PrefLabel, Aliases: richConcept.Aliases},
+		"&fields=types,directType":          {ID: richConcept.ID, PrefLabel: richConcept.PrefLabel, DirectType: richConcept.DirectType, Types: richConcept.Types},
+		"&fields=scopeNote&fields=imageUrl": {ID: richConcept.ID, PrefLabel: richConcept.PrefLabel, ScopeNote: richConcept.ScopeNote},
+	} {
+		concordances := new(mockConcordances)
+		search := new(mockSearch)
+
+		req := httptest.NewRequest("GET", "/?ids=943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"+query, nil)
+		req.Header.Add("X-Request-Id", "tid_TestSearchByIDsSelectsFields")
+		w := httptest.NewRecorder()
+
+		concordances.On("GetConcordances", "tid_TestSearchByIDsSelectsFields", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+			Return(map[string][]concepts.Identifier{
+				"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: concepts.UPPAuthority, IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}},
+			}, nil)
+		search.On("ByIDs", "tid_TestSearchByIDsSelectsFields", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+			Return(map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": richConcept}, nil)
+
+		InternalConcordances(concordances, search)(w, req)
+
+		b, _ := json.Marshal(internalConcordancesResponse{Concepts: map[string]concepts.Concept{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": expected}})
+
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		assert.Equal(t, string(b), w.Body.String(), query)
+	}
+}
+
+func TestBatchSearchByIDsSelectsFields(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"ids":["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"],"fields":["aliases"]}`))
+	req.Header.Add("X-Request-Id", "tid_TestBatchSearchByIDsSelectsFields")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestBatchSearchByIDsSelectsFields", "", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string][]concepts.Identifier{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {{Authority: concepts.UPPAuthority, IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}},
+		}, nil)
+	search.On("ByIDs", "tid_TestBatchSearchByIDsSelectsFields", []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"}).
+		Return(map[string]concepts.Concept{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump", Aliases: []string{"Trump"}, ScopeNote: "45th President of the United States"},
+		}, nil)
+
+	InternalConcordancesBatch(concordances, search)(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"concepts":{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9":{"id":"http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9","prefLabel":"Donald Trump","aliases":["Trump"]}}}`, w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestInternalConcordancesUnsupportedField(t *testing.T) {
+	message := `{"message":"Please provide supported fields instead of 'prefLabel', any of aliases, scopeNote, descriptionXML, directType, types, imageUrl, emailAddress, supersededBy"}`
+
+	req := httptest.NewRequest("GET", "/?ids=943ee5f4-bf8a-5fa8-a8da-a13e5b978db9&fields=aliases,prefLabel", nil)
+	w := httptest.NewRecorder()
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, message, strings.TrimSpace(w.Body.String()))
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"ids":["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"],"fields":["prefLabel"]}`))
+	w = httptest.NewRecorder()
+	InternalConcordancesBatch(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, message, strings.TrimSpace(w.Body.String()))
+}
+
+func TestResolveDeprecated(t *testing.T) {
+	concordances := new(mockConcordances)
+	search := new(mockSearch)
+
+	req := httptest.NewRequest("GET", "/?ids=0fca7f4d-a542-5878-a837-d89ed8a4d2ee&ids=0ccabb85-27f8-5d90-89c9-5744555b41b4&ids=dfc7bd99-3643-5106-bf48-b4a064428fab&ids=3be42e6d-a1ff-57c8-aea2-dbc6a9930619&resolve_deprecated=true&include_deprecated=false", nil)
+	req.Header.Add("X-Request-Id", "tid_TestResolveDeprecated")
+	w := httptest.NewRecorder()
+
+	concordances.On("GetConcordances", "tid_TestResolveDeprecated", "", []string{"0ccabb85-27f8-5d90-89c9-5744555b41b4", "0fca7f4d-a542-5878-a837-d89ed8a4d2ee", "3be42e6d-a1ff-57c8-aea2-dbc6a9930619", "dfc7bd99-3643-5106-bf48-b4a064428fab"}).
+		Return(map[string][]concepts.Identifier{
+			"0fca7f4d-a542-5878-a837-d89ed8a4d2ee": {{Authority: concepts.UPPAuthority, IdentifierValue: "0fca7f4d-a542-5878-a837-d89ed8a4d2ee"}},
+			"0ccabb85-27f8-5d90-89c9-5744555b41b4": {{Authority: concepts.UPPAuthority, IdentifierValue: "0ccabb85-27f8-5d90-89c9-5744555b41b4"}},
+			"dfc7bd99-3643-5106-bf48-b4a064428fab": {{Authority: concepts.UPPAuthority, IdentifierValue: "dfc7bd99-3643-5106-bf48-b4a064428fab"}},
+			"3be42e6d-a1ff-57c8-aea2-dbc6a9930619": {{Authority: concepts.UPPAuthority, IdentifierValue: "3be42e6d-a1ff-57c8-aea2-dbc6a9930619"}},
+		}, nil)
+	search.On("ByIDs", "tid_TestResolveDeprecated", []string{"0ccabb85-27f8-5d90-89c9-5744555b41b4", "0fca7f4d-a542-5878-a837-d89ed8a4d2ee", "3be42e6d-a1ff-57c8-aea2-dbc6a9930619", "dfc7bd99-3643-5106-bf48-b4a064428fab"}).
+		Return(map[string]concepts.Concept{
+			"0fca7f4d-a542-5878-a837-d89ed8a4d2ee": {ID: "http://www.ft.com/thing/0fca7f4d-a542-5878-a837-d89ed8a4d2ee", PrefLabel: "Live"},
+			"0ccabb85-27f8-5d90-89c9-5744555b41b4": {ID: "http://www.ft.com/thing/0ccabb85-27f8-5d90-89c9-5744555b41b4", PrefLabel: "Superseded", IsDeprecated: true, SupersededBy: "http://www.ft.com/thing/f78646f5-fb3a-5a20-aa60-c7c33b2a10e7"},
+			"dfc7bd99-3643-5106-bf48-b4a064428fab": {ID: "http://www.ft.com/thing/dfc7bd99-3643-5106-bf48-b4a064428fab", PrefLabel: "Deprecated", IsDeprecated: true},
+			"3be42e6d-a1ff-57c8-aea2-dbc6a9930619": {ID: "http://www.ft.com/thing/3be42e6d-a1ff-57c8-aea2-dbc6a9930619", PrefLabel: "Abandoned", IsDeprecated: true},
+		}, nil)
+
+	// deprecated concepts without supersededBy are not concorded again, the mock fails any further lookup
+	search.On("ByIDs", "tid_TestResolveDeprecated", []string{"f78646f5-fb3a-5a20-aa60-c7c33b2a10e7"}).
+		Return(map[string]concepts.Concept{
+			"f78646f5-fb3a-5a20-aa60-c7c33b2a10e7": {ID: "http://www.ft.com/thing/f78646f5-fb3a-5a20-aa60-c7c33b2a10e7", PrefLabel: "Successor"},
+		}, nil)
+
+	InternalConcordances(concordances, search)(w, req)
+
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"0fca7f4d-a542-5878-a837-d89ed8a4d2ee": {ID: "http://www.ft.com/thing/0fca7f4d-a542-5878-a837-d89ed8a4d2ee", PrefLabel: "Live"},
+			"0ccabb85-27f8-5d90-89c9-5744555b41b4": {ID: "http://www.ft.com/thing/f78646f5-fb3a-5a20-aa60-c7c33b2a10e7", PrefLabel: "Successor"},
+		},
+		NotFound: []notFound{
+			{ID: "dfc7bd99-3643-5106-bf48-b4a064428fab", Reason: reasonDeprecated},
+			{ID: "3be42e6d-a1ff-57c8-aea2-dbc6a9930619", Reason: reasonDeprecated},
+		},
+		Replaced: map[string]string{
+			"0ccabb85-27f8-5d90-89c9-5744555b41b4": "http://www.ft.com/thing/0ccabb85-27f8-5d90-89c9-5744555b41b4",
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(b), w.Body.String())
+
+	concordances.AssertExpectations(t)
+	search.AssertExpectations(t)
+}
+
+func TestResolveDeprecatedFails(t *testing.T) {
+	for _, partial := range []bool{false, true} {
+		concordances := new(mockConcordances)
+		search := new(mockSearch)
+
+		req := httptest.NewRequest("GET", "/?ids=0fca7f4d-a542-5878-a837-d89ed8a4d2ee&ids=0ccabb85-27f8-5d90-89c9-5744555b41b4&resolve_deprecated=true&partial="+strconv.FormatBool(partial), nil)
+		req.Header.Add("X-Request-Id", "tid_TestResolveDeprecatedFails")
+		w := httptest.NewRecorder()
+
+		concordances.On("GetConcordances", "tid_TestResolveDeprecatedFails", "", []string{"0ccabb85-27f8-5d90-89c9-5744555b41b4", "0fca7f4d-a542-5878-a837-d89ed8a4d2ee"}).
+			Return(map[string][]concepts.Identifier{
+				"0fca7f4d-a542-5878-a837-d89ed8a4d2ee": {{Authority: concepts.UPPAuthority, IdentifierValue: "0fca7f4d-a542-5878-a837-d89ed8a4d2ee"}},
+				"0ccabb85-27f8-5d90-89c9-5744555b41b4": {{Authority: concepts.UPPAuthority, IdentifierValue: "0ccabb85-27f8-5d90-89c9-5744555b41b4"}},
+			}, nil)
+		search.On("ByIDs", "tid_TestResolveDeprecatedFails", []string{"0ccabb85-27f8-5d90-89c9-5744555b41b4", "0fca7f4d-a542-5878-a837-d89ed8a4d2ee"}).
+			Return(map[string]concepts.Concept{
+				"0fca7f4d-a542-5878-a837-d89ed8a4d2ee": {ID: "http://www.ft.com/thing/0fca7f4d-a542-5878-a837-d89ed8a4d2ee", PrefLabel: "Live"},
+				"0ccabb85-27f8-5d90-89c9-5744555b41b4": {ID: "http://www.ft.com/thing/0ccabb85-27f8-5d90-89c9-5744555b41b4", PrefLabel: "Superseded", IsDeprecated: true, SupersededBy: "f78646f5-fb3a-5a20-aa60-c7c33b2a10e7"},
+			}, nil)
+		search.On("ByIDs", "tid_TestResolveDeprecatedFails", []string{"f78646f5-fb3a-5a20-aa60-c7c33b2a10e7"}).
+			Return(map[string]concepts.Concept(nil), errComputerSaysNo)
+
+		InternalConcordances(concordances, search)(w, req)
+
+		if !partial {
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.Equal(t, `{"message":"Successor lookup failed, please try again"}`, strings.TrimSpace(w.Body.String()))
+			continue
+		}
+
+		expectedResponse := internalConcordancesResponse{
+			Concepts: map[string]concepts.Concept{
+				"0fca7f4d-a542-5878-a837-d89ed8a4d2ee": {ID: "http://www.ft.com/thing/0fca7f4d-a542-5878-a837-d89ed8a4d2ee", PrefLabel: "Live"},
+			},
+			Errors: map[string]string{"0ccabb85-27f8-5d90-89c9-5744555b41b4": "Successor lookup failed: computer says no"},
+		}
+		b, _ := json.Marshal(expectedResponse)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, string(b), w.Body.String())
+	}
+}
+
+func TestSearchByIDsFilteredByType(t *testing.T) {
+	searchedConcepts := map[string]concepts.Concept{
+		"cbfc0c48-157f-5a48-8445-9a14f5ed767c": {ID: "http://www.ft.com/thing/cbfc0c48-157f-5a48-8445-9a14f5ed767c", PrefLabel: "Donald Trump", Type: "http://www.ft.com/ontology/person/Person"},
+		"e1a201d4-b863-5c15-b6b5-35f676c3a52b": {ID: "http://www.ft.com/thing/e1a201d4-b863-5c15-b6b5-35f676c3a52b", PrefLabel: "Apple Inc", Type: "http://www.ft.com/ontology/company/PublicCompany",
+			Types: []string{"http://www.ft.com/ontology/core/Thing", "http://www.ft.com/ontology/organisation/Organisation", "http://www.ft.com/ontology/company/PublicCompany"}},
+		"12381f53-170a-555c-ac0f-32d49b2b1921": {ID: "http://www.ft.com/thing/12381f53-170a-555c-ac0f-32d49b2b1921", PrefLabel: "Companies", Type: "http://www.ft.com/ontology/Section"},
+	}
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"cbfc0c48-157f-5a48-8445-9a14f5ed767c": {ID: "http://www.ft.com/thing/cbfc0c48-157f-5a48-8445-9a14f5ed767c", PrefLabel: "Donald Trump", Type: "http://www.ft.com/ontology/person/Person"},
+			"e1a201d4-b863-5c15-b6b5-35f676c3a52b": {ID: "http://www.ft.com/thing/e1a201d4-b863-5c15-b6b5-35f676c3a52b", PrefLabel: "Apple Inc", Type: "http://www.ft.com/ontology/company/PublicCompany"},
+		},
+		NotFound: []notFound{{ID: "12381f53-170a-555c-ac0f-32d49b2b1921", Reason: reasonTypeFiltered}},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/?ids=cbfc0c48-157f-5a48-8445-9a14f5ed767c&ids=e1a201d4-b863-5c15-b6b5-35f676c3a52b&ids=12381f53-170a-555c-ac0f-32d49b2b1921&type=person&type=http://www.ft.com/ontology/organisation/Organisation", nil),
+		httptest.NewRequest("POST", "/", strings.NewReader(`{"ids":["cbfc0c48-157f-5a48-8445-9a14f5ed767c","e1a201d4-b863-5c15-b6b5-35f676c3a52b","12381f53-170a-555c-ac0f-32d49b2b1921"],"type":["person","http://www.ft.com/ontology/organisation/Organisation"]}`)),
+	} {
+		concordances := new(mockConcordances)
+		search := new(mockSearch)
+
+		req.Header.Add("X-Request-Id", "tid_TestSearchByIDsFilteredByType")
+		w := httptest.NewRecorder()
+
+		concordances.On("GetConcordances", "tid_TestSearchByIDsFilteredByType", "", []string{"12381f53-170a-555c-ac0f-32d49b2b1921", "cbfc0c48-157f-5a48-8445-9a14f5ed767c", "e1a201d4-b863-5c15-b6b5-35f676c3a52b"}).
+			Return(map[string][]concepts.Identifier{
+				"cbfc0c48-157f-5a48-8445-9a14f5ed767c": {{Authority: concepts.UPPAuthority, IdentifierValue: "cbfc0c48-157f-5a48-8445-9a14f5ed767c"}},
+				"e1a201d4-b863-5c15-b6b5-35f676c3a52b": {{Authority: concepts.UPPAuthority, IdentifierValue: "e1a201d4-b863-5c15-b6b5-35f676c3a52b"}},
+				"12381f53-170a-555c-ac0f-32d49b2b1921": {{Authority: concepts.UPPAuthority, IdentifierValue: "12381f53-170a-555c-ac0f-32d49b2b1921"}},
+			}, nil)
+		search.On("ByIDs", "tid_TestSearchByIDsFilteredByType", []string{"12381f53-170a-555c-ac0f-32d49b2b1921", "cbfc0c48-157f-5a48-8445-9a14f5ed767c", "e1a201d4-b863-5c15-b6b5-35f676c3a52b"}).
+			Return(searchedConcepts, nil)
+
+		if req.Method == "POST" {
+			InternalConcordancesBatch(concordances, search)(w, req)
+		} else {
+			InternalConcordances(concordances, search)(w, req)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code, req.Method)
+		assert.Equal(t, string(b), w.Body.String(), req.Method)
+
+		concordances.AssertExpectations(t)
+		search.AssertExpectations(t)
+	}
+}
+
+func TestInternalConcordancesEmptyTypeSupplied(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?ids=cbfc0c48-157f-5a48-8445-9a14f5ed767c&type=", nil)
+	w := httptest.NewRecorder()
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide non-empty 'type' values"}`, strings.TrimSpace(w.Body.String()))
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"ids":["cbfc0c48-157f-5a48-8445-9a14f5ed767c"],"type":[""]}`))
+	w = httptest.NewRecorder()
+	InternalConcordancesBatch(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide non-empty 'type' values"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestSearchByIDsFTAuthorOnly(t *testing.T) {
+	isAuthor, isNotAuthor := true, false
+	searchedConcepts := map[string]concepts.Concept{
+		"9f7eb1ea-0972-5400-b0d0-156eeb748e42": {ID: "http://www.ft.com/thing/9f7eb1ea-0972-5400-b0d0-156eeb748e42", PrefLabel: "Martin Wolf", IsFTAuthor: &isAuthor},
+		"c3abe820-0404-5042-8dcb-6713d775f629": {ID: "http://www.ft.com/thing/c3abe820-0404-5042-8dcb-6713d775f629", PrefLabel: "Donald Trump", IsFTAuthor: &isNotAuthor},
+		"3ef8113f-a5d0-5396-866a-521d50ad6cb9": {ID: "http://www.ft.com/thing/3ef8113f-a5d0-5396-866a-521d50ad6cb9", PrefLabel: "Companies"},
+	}
+	expectedResponse := internalConcordancesResponse{
+		Concepts: map[string]concepts.Concept{
+			"9f7eb1ea-0972-5400-b0d0-156eeb748e42": {ID: "http://www.ft.com/thing/9f7eb1ea-0972-5400-b0d0-156eeb748e42", PrefLabel: "Martin Wolf", IsFTAuthor: &isAuthor},
+		},
+		NotFound: []notFound{
+			{ID: "c3abe820-0404-5042-8dcb-6713d775f629", Reason: reasonNotFTAuthor},
+			{ID: "3ef8113f-a5d0-5396-866a-521d50ad6cb9", Reason: reasonNotFTAuthor},
+		},
+	}
+	b, _ := json.Marshal(expectedResponse)
+
+	filtered := metrics.GetOrRegisterCounter(ftAuthorFilteredMetric, metrics.DefaultRegistry)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/?ids=9f7eb1ea-0972-5400-b0d0-156eeb748e42&ids=c3abe820-0404-5042-8dcb-6713d775f629&ids=3ef8113f-a5d0-5396-866a-521d50ad6cb9&ft_author_only=true", nil),
+		httptest.NewRequest("POST", "/", strings.NewReader(`{"ids":["9f7eb1ea-0972-5400-b0d0-156eeb748e42","c3abe820-0404-5042-8dcb-6713d775f629","3ef8113f-a5d0-5396-866a-521d50ad6cb9"],"ft_author_only":true}`)),
+	} {
+		concordances := new(mockConcordances)
+		search := new(mockSearch)
+
+		req.Header.Add("X-Request-Id", "tid_TestSearchByIDsFTAuthorOnly")
+		w := httptest.NewRecorder()
+
+		concordances.On("GetConcordances", "tid_TestSearchByIDsFTAuthorOnly", "", []string{"3ef8113f-a5d0-5396-866a-521d50ad6cb9", "9f7eb1ea-0972-5400-b0d0-156eeb748e42", "c3abe820-0404-5042-8dcb-6713d775f629"}).
+			Return(map[string][]concepts.Identifier{
+				"9f7eb1ea-0972-5400-b0d0-156eeb748e42": {{Authority: concepts.UPPAuthority, IdentifierValue: "9f7eb1ea-0972-5400-b0d0-156eeb748e42"}},
+				"c3abe820-0404-5042-8dcb-6713d775f629": {{Authority: concepts.UPPAuthority, IdentifierValue: "c3abe820-0404-5042-8dcb-6713d775f629"}},
+				"3ef8113f-a5d0-5396-866a-521d50ad6cb9": {{Authority: concepts.UPPAuthority, IdentifierValue: "3ef8113f-a5d0-5396-866a-521d50ad6cb9"}},
+			}, nil)
+		search.On("ByIDs", "tid_TestSearchByIDsFTAuthorOnly", []string{"3ef8113f-a5d0-5396-866a-521d50ad6cb9", "9f7eb1ea-0972-5400-b0d0-156eeb748e42", "c3abe820-0404-5042-8dcb-6713d775f629"}).
+			Return(searchedConcepts, nil)
+
+		before := filtered.Count()
+		if req.Method == "POST" {
+			InternalConcordancesBatch(concordances, search)(w, req)
+		} else {
+			InternalConcordances(concordances, search)(w, req)
+		}
+
+		assert.Equal(t, http.StatusOK, w.Code, req.Method)
+		assert.Equal(t, string(b), w.Body.String(), req.Method)
+		assert.Equal(t, int64(2), filtered.Count()-before, req.Method)
+
+		concordances.AssertExpectations(t)
+		search.AssertExpectations(t)
+	}
+}
+
+func TestInternalConcordancesInvalidFTAuthorOnlyParamSupplied(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?ids=9f7eb1ea-0972-5400-b0d0-156eeb748e42&ft_author_only=perhaps", nil)
+	w := httptest.NewRecorder()
+
+	InternalConcordances(nil, nil)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"message":"Please provide a valid boolean for 'ft_author_only' query parameter"}`, strings.TrimSpace(w.Body.String()))
+}
+
+func TestMergeConcordancesAndConcepts(t *testing.T) {
+	requested := concordanceRequest{
+		IDs: []string{
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9",
+			"9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e",
+			"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9",
+			"",
+			"not-a-uuid",
+			"eee00ba1-9e86-5758-a827-1193c04362b5",
+			"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c",
+			"e3e7590f-1928-53ef-8ff3-99d1432fba0b",
+			"e3e7590f-1928-53ef-8ff3-99d1432fba0b",
+		},
+		Identifiers: []concepts.Identifier{
+			{Authority: "TME", IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="},
+			{Authority: "SMARTLOGIC", IdentifierValue: "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"},
+			{Authority: "SMARTLOGIC", IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+		},
+		Authority: concepts.NoAuthority,
+	}.requestedIDs()
+
+	identifiers := map[string][]concepts.Identifier{
+		"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {
+			{Authority: concepts.UPPAuthority, IdentifierValue: "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"},
+			{Authority: concepts.UPPAuthority, IdentifierValue: "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e"},
+			{Authority: concepts.TMEAuthority, IdentifierValue: "TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="},
+		},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {{Authority: concepts.UPPAuthority, IdentifierValue: "eee00ba1-9e86-5758-a827-1193c04362b5"}},
+		"136a2b54-b2ae-57a0-a0ff-79e1f0358b6c": {{Authority: concepts.UPPAuthority, IdentifierValue: "136a2b54-b2ae-57a0-a0ff-79e1f0358b6c"}},
+	}
+	searchedConcepts := map[string]concepts.Concept{
+		"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9": {ID: "http://www.ft.com/thing/943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", PrefLabel: "Donald Trump"},
+		"eee00ba1-9e86-5758-a827-1193c04362b5": {ID: "http://www.ft.com/thing/eee00ba1-9e86-5758-a827-1193c04362b5", PrefLabel: "NOT Donald Trump", IsDeprecated: true},
+	}
+
+	merged, missing := mergeConcordancesAndConcepts(requested, identifiers, searchedConcepts, conceptFilter{})
+
+	trump := searchedConcepts["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"]
+	assert.Equal(t, map[string]concepts.Concept{
+		"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9":          trump,
+		"9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e":          trump,
+		"TME:TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4=": trump,
+	}, merged)
+	assert.Equal(t, []notFound{
+		{ID: "not-a-uuid", Reason: reasonInvalidID},
+		{ID: "eee00ba1-9e86-5758-a827-1193c04362b5", Reason: reasonDeprecated},
+		{ID: "136a2b54-b2ae-57a0-a0ff-79e1f0358b6c", Reason: reasonMissingFromSearch},
+		{ID: "e3e7590f-1928-53ef-8ff3-99d1432fba0b", Reason: reasonNoConcordance},
+		{ID: "SMARTLOGIC:9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e", Reason: reasonNoConcordance},
+		{ID: "SMARTLOGIC:943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", Reason: reasonNoConcordance},
+	}, missing)
+
+	assert.ElementsMatch(t, []string{"943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", "9233a259-8b5d-5ff2-b9a9-852eaf0c4e9e", "943ee5f4-bf8a-5fa8-a8da-a13e5b978db9", "TME:TnN0ZWluX1BOX1BvbGl0aWNpYW5fMTY4OQ==-UE4="},
+		requestedIDsConcordedTo(newRequestedIndex(requested), identifiers["943ee5f4-bf8a-5fa8-a8da-a13e5b978db9"]))
+}
+
+func BenchmarkMergeConcordancesAndConcepts(b *testing.B) {
+	for _, size := range []struct{ concepts, identifiersPerConcept int }{{10, 5}, {100, 20}, {1000, 20}} {
+		requested, identifiers, searchedConcepts := mergeBenchmarkData(size.concepts, size.identifiersPerConcept)
+		b.Run(fmt.Sprintf("%d concepts with %d identifiers", size.concepts, size.identifiersPerConcept), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mergeConcordancesAndConcepts(requested, identifiers, searchedConcepts, conceptFilter{includeDeprecated: true})
+			}
+		})
+	}
+}
+
+func BenchmarkRequestedIDsConcordedTo(b *testing.B) {
+	requested, identifiers, _ := mergeBenchmarkData(1000, 20)
+	index := newRequestedIndex(requested)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, concorded := range identifiers {
+			requestedIDsConcordedTo(index, concorded)
+		}
+	}
+}
+
+// mergeBenchmarkData requests every identifier of the given number of concepts, half by uuid and half by TME id, along with as many ids which concord to nothing
+func mergeBenchmarkData(conceptCount, identifiersPerConcept int) ([]requestedID, map[string][]concepts.Identifier, map[string]concepts.Concept) {
+	var requested []requestedID
+	identifiers := make(map[string][]concepts.Identifier)
+	searchedConcepts := make(map[string]concepts.Concept)
+
+	for c := 0; c < conceptCount; c++ {
+		uuid := fmt.Sprintf("%08x-0000-5000-8000-000000000000", c)
+		searchedConcepts[uuid] = concepts.Concept{ID: "http://www.ft.com/thing/" + uuid}
+
+		for i := 0; i < identifiersPerConcept; i++ {
+			var identifier concepts.Identifier
+			if i%2 == 0 {
+				identifier = concepts.Identifier{Authority: concepts.UPPAuthority, IdentifierValue: fmt.Sprintf("%08x-%04x-5000-8000-000000000000", c, i)}
+				requested = append(requested, newRequestedID(identifier.IdentifierValue, concepts.NoAuthority, identifier.IdentifierValue))
+			} else {
+				identifier = concepts.Identifier{Authority: concepts.TMEAuthority, IdentifierValue: fmt.Sprintf("MTQ4-%08X%04X", c, i)}
+				requested = append(requested, newRequestedID("TME:"+identifier.IdentifierValue, concepts.TMEAuthority, identifier.IdentifierValue))
+			}
+			identifiers[uuid] = append(identifiers[uuid], identifier)
+
+			unknown := fmt.Sprintf("%08x-%04x-5000-8000-ffffffffffff", c, i)
+			requested = append(requested, newRequestedID(unknown, concepts.NoAuthority, unknown))
+		}
+	}
+	return requested, identifiers, searchedConcepts
+}
@@ -1,7 +1,9 @@
 package resources
 
 import (
+	"context"
 	"sort"
+	"sync"
 
 	fthealth "github.com/Financial-Times/go-fthealth/v1_1"
 	"github.com/Financial-Times/internal-concordances/concepts"
@@ -10,9 +12,15 @@ import (
 
 type mockConcordances struct {
 	mock.Mock
+	ctxLock sync.Mutex
+	ctx     context.Context
 }
 
-func (m *mockConcordances) GetConcordances(tid, authority string, uuids ...string) (map[string][]concepts.Identifier, error) {
+func (m *mockConcordances) GetConcordances(ctx context.Context, tid, authority string, uuids ...string) (map[string][]concepts.Identifier, error) {
+	m.ctxLock.Lock()
+	m.ctx = ctx
+	m.ctxLock.Unlock()
+	uuids = append([]string(nil), uuids...)
 	sort.Strings(uuids)
 	args := m.Called(tid, authority, uuids)
 	return args.Get(0).(map[string][]concepts.Identifier), args.Error(1)
@@ -25,9 +33,12 @@ func (m *mockConcordances) Check() fthealth.Check {
 
 type mockSearch struct {
 	mock.Mock
+	ctx context.Context
 }
 
-func (m *mockSearch) ByIDs(tid string, uuids ...string) (map[string]concepts.Concept, error) {
+func (m *mockSearch) ByIDs(ctx context.Context, tid string, uuids ...string) (map[string]concepts.Concept, error) {
+	m.ctx = ctx
+	uuids = append([]string(nil), uuids...)
 	sort.Strings(uuids)
 	args := m.Called(tid, uuids)
 	return args.Get(0).(map[string]concepts.Concept), args.Error(1)
@@ -37,3 +48,27 @@ func (m *mockSearch) Check() fthealth.Check {
 	args := m.Called()
 	return args.Get(0).(fthealth.Check)
 }
+
+type mockCache struct {
+	mock.Mock
+}
+
+func (m *mockCache) Name() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *mockCache) Stats() concepts.CacheStats {
+	args := m.Called()
+	return args.Get(0).(concepts.CacheStats)
+}
+
+func (m *mockCache) Purge(authority string, ids ...string) int {
+	args := m.Called(authority, ids)
+	return args.Int(0)
+}
+
+func (m *mockCache) Flush() int {
+	args := m.Called()
+	return args.Int(0)
+}